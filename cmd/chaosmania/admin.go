@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Causely/chaosmania/pkg/actions"
+)
+
+// The admin endpoints show the registered actions, the services loaded from
+// /etc/chaosmania/services.yaml and the background services, with secrets redacted.
+
+func serveAdminJSON(w http.ResponseWriter, r *http.Request, describe func() any) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "Error: Method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(describe())
+}
+
+func handleAdminActions(w http.ResponseWriter, r *http.Request) {
+	serveAdminJSON(w, r, func() any { return actions.ActionInfos() })
+}
+
+// handleAdminServices checks the health of every service
+func handleAdminServices(w http.ResponseWriter, r *http.Request) {
+	serveAdminJSON(w, r, func() any { return actions.Manager.Info(r.Context()) })
+}
+
+func handleAdminBackground(w http.ResponseWriter, r *http.Request) {
+	serveAdminJSON(w, r, func() any { return actions.BackgroundManager.Info() })
+}
@@ -9,17 +9,21 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/actions"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli/v2"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/zap"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 	"gopkg.in/yaml.v2"
 )
 
@@ -29,6 +33,13 @@ func startPprofServer() {
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer,
+		promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		},
+	))
+
 	server := &http.Server{Addr: ":8080", Handler: mux}
 	go func() {
 		_ = server.ListenAndServe()
@@ -68,31 +79,29 @@ func loadPlan(logger *zap.Logger, path string) (actions.Plan, map[string]any, er
 	return plan, pkg.Convert(raw).(map[string]any), nil
 }
 
-func doRequest(req *http.Request, timeout *time.Duration) (*http.Response, error) {
+// doRequest sends the request using the given transport, or http.DefaultTransport if nil
+func doRequest(req *http.Request, timeout *time.Duration, transport http.RoundTripper) (*http.Response, error) {
 	client := http.Client{}
 
 	if timeout != nil {
 		client.Timeout = *timeout
 	}
 
-	if pkg.IsDatadogEnabled() {
-		client := httptrace.WrapClient(&client)
-		return client.Do(req)
-	} else if pkg.IsOpenTelemetryEnabled() {
-		client.Transport = otelhttp.NewTransport(http.DefaultTransport)
-		return client.Do(req)
-	} else {
-		return client.Do(req)
+	if transport == nil {
+		transport = http.DefaultTransport
 	}
+	client.Transport = tracing.Get().WrapTransport(transport)
+
+	return client.Do(req)
 }
 
-func sendRequest(logger *zap.Logger, payload map[string]any, host string, port int64, headers map[string]string) error {
+func sendRequest(ctx context.Context, logger *zap.Logger, payload map[string]any, host string, port int64, headers map[string]string, recorder *workerRecorder) error {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s:%d/", host, port), bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("http://%s:%d/", host, port), bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		logger.Error("failed to create request", zap.Error(err))
 		return err
@@ -103,12 +112,17 @@ func sendRequest(logger *zap.Logger, payload map[string]any, host string, port i
 		req.Header.Set(k, v)
 	}
 
-	resp, err := doRequest(req, nil)
+	start := time.Now()
+	resp, err := doRequest(req, nil, nil)
 
 	if err != nil {
 		logger.Error("Request failed", zap.String("host", host), zap.Int64("port", port), zap.Error(err))
+		recordRequest(logger, recorder, start, payloadBytes, 0, time.Since(start), err)
 		return err
 	}
+	defer resp.Body.Close()
+
+	recordRequest(logger, recorder, start, payloadBytes, resp.StatusCode, time.Since(start), nil)
 
 	if resp.StatusCode == 400 {
 		s, err := io.ReadAll(resp.Body)
@@ -123,6 +137,43 @@ func sendRequest(logger *zap.Logger, payload map[string]any, host string, port i
 	return nil
 }
 
+func recordRequest(logger *zap.Logger, recorder *workerRecorder, start time.Time, payload []byte, status int, took time.Duration, err error) {
+	if recErr := recorder.Record(start, payload, status, took, err); recErr != nil {
+		logger.Warn("failed to record request", zap.Error(recErr))
+	}
+}
+
+// planSectionTimeout bounds the plan-wide setup and teardown requests, so a server that
+// hangs cannot keep the client from exiting
+const planSectionTimeout = 5 * time.Minute
+
+// executePlanSection sends the plan-wide setup or teardown section, if the plan has one.
+// The request is cancelled with ctx or after planSectionTimeout.
+func executePlanSection(ctx context.Context, logger *zap.Logger, name string, raw map[string]any, host string, port int64, headers map[string]string, recorder *Recorder) error {
+	s, ok := raw[name]
+	if !ok {
+		return nil
+	}
+
+	payload, ok := s.(map[string]any)
+	if !ok {
+		return fmt.Errorf("plan %s section must be a workload, got %T", name, s)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, planSectionTimeout)
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Executing plan %s section", name))
+	err := sendRequest(ctx, logger, payload, host, port, headers, recorder.Worker("", name))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Plan %s section failed", name), zap.Error(err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Plan %s section completed successfully", name))
+	return nil
+}
+
 type statisticCounters struct {
 	Errors               uint64
 	Requests             uint64
@@ -135,7 +186,7 @@ type statistics struct {
 	allStatusCodes map[int]int
 }
 
-func runWorker(logger *zap.Logger, stats *statistics, timeout time.Duration, ctx context.Context, delay time.Duration, host string, port int64, payloadBytes []byte, headers map[string]string) {
+func runWorker(logger *zap.Logger, stats *statistics, timeout time.Duration, ctx context.Context, delay time.Duration, host string, port int64, payloadBytes []byte, headers map[string]string, transport http.RoundTripper, recorder *workerRecorder, metrics *workerMetrics) {
 	statusCodes := make(map[int]int)
 
 	// Use 10sec client timeout by default, but allow the client to set a custom timeout
@@ -179,12 +230,14 @@ loop:
 				}
 			}
 
-			resp, err := doRequest(req, &to)
+			metrics.RequestStarted()
+			resp, err := doRequest(req, &to, transport)
 			took := time.Since(start)
 
 			if err != nil {
 				// Check if the error is due to context cancellation
 				if ctx.Err() != nil {
+					metrics.RequestCancelled()
 					switch ctx.Err() {
 					case context.Canceled:
 						logger.Debug("Request cancelled due to command completion")
@@ -196,9 +249,14 @@ loop:
 					break loop
 				}
 				atomic.AddUint64(&stats.counters.Errors, 1)
+				recordRequest(logger, recorder, start, payloadBytes, 0, took, err)
+				metrics.RequestDone(0, took, err)
 				continue
 			}
 
+			recordRequest(logger, recorder, start, payloadBytes, resp.StatusCode, took, nil)
+			metrics.RequestDone(resp.StatusCode, took, nil)
+
 			if resp.StatusCode == 400 {
 				s, err := io.ReadAll(resp.Body)
 				if err != nil {
@@ -207,7 +265,7 @@ loop:
 				}
 
 				logger.Warn(string(s))
-			} else if resp.StatusCode > 400 {
+			} else if isErrorStatus(resp.StatusCode) {
 				atomic.AddUint64(&stats.counters.Errors, 1)
 			}
 
@@ -227,11 +285,11 @@ loop:
 	}
 }
 
-func executePhase(logger *zap.Logger, phase actions.Phase, raw map[string]any, host string, port int64, header map[string]string, ctx context.Context, durations *actions.PhaseDurations, phaseIndex int, reporter *actions.Reporter) error {
+func executePhase(logger *zap.Logger, phase actions.Phase, raw map[string]any, host string, port int64, header map[string]string, ctx context.Context, durations *actions.PhaseDurations, phaseIndex int, reporter *actions.Reporter, recorder *Recorder, metrics *clientMetrics) error {
 	// Setup
 	if s, ok := raw["setup"]; ok {
 		logger.Info("Executing setup section")
-		err := sendRequest(logger, s.(map[string]any), host, port, header)
+		err := sendRequest(context.Background(), logger, s.(map[string]any), host, port, header, recorder.Worker(phase.Name, "setup"))
 		if err != nil {
 			logger.Error("Setup section failed", zap.Error(err))
 			return err
@@ -253,6 +311,25 @@ func executePhase(logger *zap.Logger, phase actions.Phase, raw map[string]any, h
 	for i, w := range phase.Client.Workers {
 		logger.Info(fmt.Sprintf("Starting workers: %v", w.Instances))
 
+		// Apply transport settings and emulate bad network conditions for this worker group, if configured
+		if w.Network != nil {
+			logger.Info(fmt.Sprintf("Emulating network: latency %v (+/- %v), bandwidth %v B/s, drop rate %v, fail rate %v",
+				w.Network.Latency, w.Network.Jitter, w.Network.Bandwidth, w.Network.DropRate, w.Network.FailRate))
+		}
+		if t := w.Transport; t != nil {
+			logger.Info(fmt.Sprintf("Transport: keep-alive %v, max idle conns %v (%v per host), per worker %v, http2 %v, new connection every %v requests",
+				!t.DisableKeepAlives, t.MaxIdleConns, t.MaxIdleConnsPerHost, t.PerWorker, t.HTTP2, t.NewConnectionEvery))
+		}
+
+		perWorkerTransport := w.Transport != nil && w.Transport.PerWorker
+		var transport http.RoundTripper
+		if !perWorkerTransport {
+			transport = newWorkerTransport(&w)
+			if transport != nil {
+				defer closeIdleConnections(transport)
+			}
+		}
+
 		// Run workload
 		var wg sync.WaitGroup
 		wg.Add(int(w.Instances))
@@ -318,7 +395,14 @@ func executePhase(logger *zap.Logger, phase actions.Phase, raw map[string]any, h
 			go func(workerNum int, stats *statistics) {
 				defer wg.Done()
 				defer allWorkersWg.Done()
-				runWorker(logger, stats, w.Timeout, workerCtx, w.Delay, host, port, payloadBytes, header)
+
+				transport := transport
+				if perWorkerTransport {
+					transport = newWorkerTransport(&w)
+					defer closeIdleConnections(transport)
+				}
+
+				runWorker(logger, stats, w.Timeout, workerCtx, w.Delay, host, port, payloadBytes, header, transport, recorder.Worker(phase.Name, fmt.Sprintf("%d-%d", i+1, workerNum+1)), metrics.Worker(phase.Name, i+1))
 				if workerCtx.Err() != nil {
 					logger.Debug(fmt.Sprintf("Worker %d-%d completed due to: %v", i+1, workerNum+1, workerCtx.Err()))
 				}
@@ -360,7 +444,7 @@ func executePhase(logger *zap.Logger, phase actions.Phase, raw map[string]any, h
 	// Always run teardown, even if context is cancelled
 	if t, ok := raw["teardown"]; ok {
 		logger.Info("Executing teardown section")
-		err := sendRequest(logger, t.(map[string]any), host, port, header)
+		err := sendRequest(context.Background(), logger, t.(map[string]any), host, port, header, recorder.Worker(phase.Name, "teardown"))
 		if err != nil {
 			logger.Error("Teardown section failed", zap.Error(err))
 			// Don't return the error since we want to ensure the context cancellation propagates
@@ -380,6 +464,7 @@ func command_client(logger *zap.Logger, ctx *cli.Context) error {
 	runtimeDurationStr := ctx.String("runtime-duration")
 	repeatsPerPhase := ctx.Int("repeats-per-phase")
 	phasePattern := ctx.String("phase-pattern")
+	recordPath := ctx.String("record")
 
 	startPprofServer()
 	// Validate repeats-per-phase
@@ -461,6 +546,29 @@ func command_client(logger *zap.Logger, ctx *cli.Context) error {
 	shutdown := InitOTLPProvider(logger)
 	defer shutdown()
 
+	// Trace the requests with the backend enabled by the environment
+	tracing.SetProvider(tracing.FromEnvironment())
+
+	// Record all requests sent by the client, if requested
+	var recorder *Recorder
+	if recordPath != "" {
+		recorder, err = NewRecorder(recordPath)
+		if err != nil {
+			return fmt.Errorf("failed to create recording: %w", err)
+		}
+
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				logger.Warn("failed to close recording", zap.Error(err))
+			}
+		}()
+
+		logger.Info(fmt.Sprintf("Recording requests to %s", recordPath))
+	}
+
+	// Client metrics are labeled with the plan file name
+	metrics := newClientMetrics(strings.TrimSuffix(filepath.Base(planPath), filepath.Ext(planPath)))
+
 	// Create PhaseDurations instance for all duration calculations
 	durations := actions.NewPhaseDurations(runtimeDuration, &plan, phaseRepeats)
 
@@ -473,13 +581,31 @@ func command_client(logger *zap.Logger, ctx *cli.Context) error {
 	// Log plan summary
 	reporter.LogPlanSummary()
 
-	// Create root context for cancellation propagation
-	rootCtx, cancel := context.WithCancel(context.Background())
+	// Create root context for cancellation propagation, cancelled on SIGINT/SIGTERM
+	rootCtx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer func() {
 		logger.Info("Command completed, initiating graceful shutdown...")
 		cancel()
 	}()
 
+	// The first signal starts the graceful shutdown, restore the default handling so a
+	// second one kills the client, for example while a hung server blocks the teardown
+	go func() {
+		<-rootCtx.Done()
+		cancel()
+	}()
+
+	// Plan-wide setup runs once before the first phase
+	err = executePlanSection(rootCtx, logger, "setup", raw, host, port, headers, recorder)
+	if err != nil {
+		return err
+	}
+
+	// Plan-wide teardown runs once after the last phase, even if the run is aborted
+	defer func() {
+		_ = executePlanSection(context.Background(), logger, "teardown", raw, host, port, headers, recorder)
+	}()
+
 	// Create pattern executor
 	patternExecutor := actions.NewPatternExecutor(plan.Pattern, len(plan.Phases))
 
@@ -507,7 +633,9 @@ func command_client(logger *zap.Logger, ctx *cli.Context) error {
 
 		// Execute current phase
 		logger.Info(fmt.Sprintf("Executing phase %d for %.0f seconds", currentPhase+1, phaseDuration.Seconds()))
-		err := executePhase(logger, plan.Phases[currentPhase], raw["phases"].([]any)[currentPhase].(map[string]any), host, port, headers, phaseCtx, durations, currentPhase, reporter)
+		metrics.PhaseStarted(plan.Phases[currentPhase].Name, phaseExecutions[currentPhase]+1)
+		err := executePhase(logger, plan.Phases[currentPhase], raw["phases"].([]any)[currentPhase].(map[string]any), host, port, headers, phaseCtx, durations, currentPhase, reporter, recorder, metrics)
+		metrics.PhaseEnded(plan.Phases[currentPhase].Name)
 
 		// Always cancel the phase context after execution
 		phaseCancel()
@@ -518,6 +646,9 @@ func command_client(logger *zap.Logger, ctx *cli.Context) error {
 		// Check if we should advance to the next phase
 		if err == context.DeadlineExceeded {
 			logger.Debug(fmt.Sprintf("Phase %d completed after reaching its time limit", currentPhase+1))
+		} else if err == context.Canceled && rootCtx.Err() != nil {
+			logger.Info("Execution interrupted, stopping")
+			return nil
 		} else if err != nil {
 			return err
 		}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var clientRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_client_requests_total",
+	Help: "The number of requests sent by the client",
+}, []string{"plan", "phase", "worker_group", "status_code"})
+
+var clientErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_client_errors_total",
+	Help: "The number of failed client requests by kind",
+}, []string{"plan", "phase", "worker_group", "kind"})
+
+var clientRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "chaosmania_client_request_duration_seconds",
+	Help: "The latency of requests sent by the client",
+}, []string{"plan", "phase", "worker_group"})
+
+var clientRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chaosmania_client_requests_in_flight",
+	Help: "The number of client requests waiting for a response",
+}, []string{"plan", "phase", "worker_group"})
+
+var clientPhaseActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chaosmania_client_phase_active",
+	Help: "1 while the phase is executed by the client, 0 otherwise",
+}, []string{"plan", "phase"})
+
+var clientPhaseRepeat = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chaosmania_client_phase_repeat",
+	Help: "The repeat of the phase currently or last executed by the client",
+}, []string{"plan", "phase"})
+
+// clientMetrics records the client metrics of a plan
+type clientMetrics struct {
+	plan string
+}
+
+func newClientMetrics(plan string) *clientMetrics {
+	return &clientMetrics{plan: plan}
+}
+
+func (m *clientMetrics) PhaseStarted(phase string, repeat int) {
+	clientPhaseActive.WithLabelValues(m.plan, phase).Set(1)
+	clientPhaseRepeat.WithLabelValues(m.plan, phase).Set(float64(repeat))
+}
+
+func (m *clientMetrics) PhaseEnded(phase string) {
+	clientPhaseActive.WithLabelValues(m.plan, phase).Set(0)
+}
+
+// Worker returns the metrics of a worker group within a phase
+func (m *clientMetrics) Worker(phase string, group int) *workerMetrics {
+	labels := prometheus.Labels{
+		"plan":         m.plan,
+		"phase":        phase,
+		"worker_group": strconv.Itoa(group),
+	}
+
+	return &workerMetrics{
+		requests: clientRequests.MustCurryWith(labels),
+		errors:   clientErrors.MustCurryWith(labels),
+		duration: clientRequestDuration.With(labels),
+		inFlight: clientRequestsInFlight.With(labels),
+	}
+}
+
+type workerMetrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration prometheus.Observer
+	inFlight prometheus.Gauge
+}
+
+func (m *workerMetrics) RequestStarted() {
+	m.inFlight.Inc()
+}
+
+// RequestCancelled records a request aborted because the worker is stopping
+func (m *workerMetrics) RequestCancelled() {
+	m.inFlight.Dec()
+}
+
+// RequestDone records a finished request; status is 0 if no response was received
+func (m *workerMetrics) RequestDone(status int, took time.Duration, err error) {
+	m.inFlight.Dec()
+	m.requests.WithLabelValues(strconv.Itoa(status)).Inc()
+	m.duration.Observe(took.Seconds())
+
+	if kind := errorKind(status, err); kind != "" {
+		m.errors.WithLabelValues(kind).Inc()
+	}
+}
+
+// errorKind classifies a failed request, or returns "" if the request succeeded
+func errorKind(status int, err error) string {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, errEmulatedFailure):
+			return "emulated"
+		case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+			return "timeout"
+		case errors.Is(err, syscall.ECONNREFUSED):
+			return "connection_refused"
+		case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, net.ErrClosed):
+			return "connection_closed"
+		default:
+			return "other"
+		}
+	}
+
+	switch {
+	case status >= 500:
+		return "http_5xx"
+	case isErrorStatus(status):
+		return "http_4xx"
+	}
+
+	return ""
+}
+
+// isErrorStatus reports whether a response status counts as a failed request, in the
+// client stats as well as the metrics. A 400 means the server rejected the workload,
+// which is logged instead.
+func isErrorStatus(status int) bool {
+	return status > 400
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/actions"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"gopkg.in/yaml.v2"
+)
+
+// Endpoint runs a fixed workload for requests to a route, like the handler of a REST service
+type Endpoint struct {
+	// Route is a net/http pattern, for example "GET /products/{id}"
+	Route       string           `json:"route"`
+	Status      int              `json:"status"`
+	ErrorStatus int              `json:"error_status"`
+	Body        string           `json:"body"`
+	Faults      []FaultRule      `json:"faults"`
+	Workload    actions.Workload `json:"workload"`
+}
+
+type Endpoints struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// ENDPOINTS are served next to the generic workload endpoint at /
+var ENDPOINTS []Endpoint
+
+// endpointsLoaded is the start of the time windows of the endpoint faults
+var endpointsLoaded time.Time
+
+func validStatus(status int) bool {
+	return status == 0 || (status >= 100 && status <= 599)
+}
+
+func (e *Endpoint) Verify() error {
+	if e.Route == "" {
+		return fmt.Errorf("endpoint route is required")
+	}
+	if !validStatus(e.Status) || !validStatus(e.ErrorStatus) {
+		return fmt.Errorf("endpoint %s: invalid status code", e.Route)
+	}
+
+	for i := range e.Faults {
+		if e.Faults[i].Name == "" {
+			e.Faults[i].Name = fmt.Sprintf("%s fault-%d", e.Route, i+1)
+		}
+
+		if err := e.Faults[i].Verify(); err != nil {
+			return fmt.Errorf("endpoint %s: %w", e.Route, err)
+		}
+	}
+
+	if err := e.Workload.Verify(); err != nil {
+		return fmt.Errorf("endpoint %s: %w", e.Route, err)
+	}
+
+	return nil
+}
+
+func LoadEndpointsFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	err = yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := pkg.ParseConfig[Endpoints](pkg.Convert(raw).(map[string]any))
+	if err != nil {
+		return err
+	}
+
+	for i := range endpoints.Endpoints {
+		if err := endpoints.Endpoints[i].Verify(); err != nil {
+			return err
+		}
+	}
+
+	// Catch invalid or conflicting routes now instead of when the server starts
+	_, err = newMux(endpoints.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	ENDPOINTS = endpoints.Endpoints
+	endpointsLoaded = time.Now()
+	return nil
+}
+
+type handlerRegistry interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// registerEndpoints adds the endpoints to the mux, it fails if a route is invalid or
+// conflicts with another one
+func registerEndpoints(mux handlerRegistry, endpoints []Endpoint) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid endpoint route: %v", r)
+		}
+	}()
+
+	for i := range endpoints {
+		mux.HandleFunc(endpoints[i].Route, endpoints[i].handle)
+	}
+
+	return nil
+}
+
+func (e *Endpoint) handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	// Name the server span after the route instead of the path
+	tracing.Get().SetRoute(r.Context(), e.Route)
+
+	var faults []FaultRule
+	elapsed := time.Since(endpointsLoaded)
+	for i := range e.Faults {
+		if e.Faults[i].matches(r, elapsed) {
+			faults = append(faults, e.Faults[i])
+		}
+	}
+
+	if injectFaults(w, r, faults) {
+		return
+	}
+
+	serveWorkload(w, r, &e.Workload, start, workloadResponse{
+		Status:      e.Status,
+		ErrorStatus: e.ErrorStatus,
+		Body:        e.Body,
+	})
+}
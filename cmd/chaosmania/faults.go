@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/actions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+var injectedFaults = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_injected_faults_total",
+	Help: "The number of faults injected by the fault rules",
+}, []string{"rule", "fault"})
+
+// FaultRule injects a fault into a fraction of the requests it matches
+type FaultRule struct {
+	Name string `json:"name"`
+
+	// Match, all conditions are optional. Path matches the path and the paths below it.
+	Path    string            `json:"path"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+
+	// Probability of injecting the fault into a matching request, defaults to 1 if not set.
+	// A probability of 0 disables the rule.
+	Probability *float64 `json:"probability"`
+
+	// Time window relative to when the rules were loaded, Until 0 means forever
+	After pkg.Duration `json:"after"`
+	Until pkg.Duration `json:"until"`
+
+	// Fault, latency is added before the status or reset is applied
+	Latency pkg.Duration `json:"latency"`
+	Status  int          `json:"status"`
+	Reset   bool         `json:"reset"`
+}
+
+type FaultRules struct {
+	Rules []FaultRule `json:"rules"`
+}
+
+func (rule *FaultRule) Verify() error {
+	if rule.Probability != nil && (*rule.Probability < 0 || *rule.Probability > 1) {
+		return fmt.Errorf("rule %s: probability must be between 0 and 1", rule.Name)
+	}
+	if rule.Status != 0 && (rule.Status < 100 || rule.Status > 599) {
+		return fmt.Errorf("rule %s: invalid status code %d", rule.Name, rule.Status)
+	}
+	if rule.Until.Duration != 0 && rule.Until.Duration <= rule.After.Duration {
+		return fmt.Errorf("rule %s: until must be after after", rule.Name)
+	}
+	if rule.Latency.Duration == 0 && rule.Status == 0 && !rule.Reset {
+		return fmt.Errorf("rule %s: no fault configured", rule.Name)
+	}
+
+	return nil
+}
+
+func (rule *FaultRule) matches(r *http.Request, elapsed time.Duration) bool {
+	if elapsed < rule.After.Duration || (rule.Until.Duration != 0 && elapsed >= rule.Until.Duration) {
+		return false
+	}
+
+	if rule.Path != "" && !pathMatches(r.URL.Path, rule.Path) {
+		return false
+	}
+
+	if rule.Method != "" && !strings.EqualFold(rule.Method, r.Method) {
+		return false
+	}
+
+	for k, v := range rule.Headers {
+		if r.Header.Get(k) != v {
+			return false
+		}
+	}
+
+	// Probability defaults to 1 if not set
+	if rule.Probability == nil {
+		return true
+	}
+
+	return actions.RandFromContext(r.Context()).Float64() < *rule.Probability
+}
+
+// pathMatches reports whether path is the rule path or below it, so /checkout matches
+// /checkout/123 but not /checkouts
+func pathMatches(path string, rulePath string) bool {
+	return path == rulePath || strings.HasPrefix(path, strings.TrimSuffix(rulePath, "/")+"/")
+}
+
+func parseFaultRules(data []byte) ([]FaultRule, error) {
+	var raw map[string]any
+	err := yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := pkg.ParseConfig[FaultRules](pkg.Convert(raw).(map[string]any))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules.Rules {
+		if rules.Rules[i].Name == "" {
+			rules.Rules[i].Name = fmt.Sprintf("rule-%d", i+1)
+		}
+
+		if err := rules.Rules[i].Verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules.Rules, nil
+}
+
+// FaultInjector is a middleware injecting faults into requests based on rules
+type FaultInjector struct {
+	mu     sync.RWMutex
+	rules  []FaultRule
+	loaded time.Time
+}
+
+var FAULTS *FaultInjector = NewFaultInjector()
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{
+		loaded: time.Now(),
+	}
+}
+
+// SetRules replaces the rules and restarts their time windows
+func (f *FaultInjector) SetRules(rules []FaultRule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = rules
+	f.loaded = time.Now()
+}
+
+func (f *FaultInjector) LoadFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	rules, err := parseFaultRules(data)
+	if err != nil {
+		return err
+	}
+
+	f.SetRules(rules)
+	return nil
+}
+
+func (f *FaultInjector) matchingRules(r *http.Request) []FaultRule {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	elapsed := time.Since(f.loaded)
+
+	var matching []FaultRule
+	for i := range f.rules {
+		if f.rules[i].matches(r, elapsed) {
+			matching = append(matching, f.rules[i])
+		}
+	}
+
+	return matching
+}
+
+// isInternalPath reports whether the path serves health checks, metrics, profiling or admin requests
+func isInternalPath(path string) bool {
+	return path == "/health" ||
+		path == "/ready" ||
+		path == "/metrics" ||
+		strings.HasPrefix(path, "/debug/") ||
+		strings.HasPrefix(path, "/admin/")
+}
+
+func (f *FaultInjector) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isInternalPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Fault decisions use the random number generator of the request, like its actions
+		r = r.WithContext(actions.NewRandContext(r.Context(), r.Header))
+
+		if injectFaults(w, r, f.matchingRules(r)) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// injectFaults injects the faults of the matching rules, it returns true if a fault
+// answered or aborted the request
+func injectFaults(w http.ResponseWriter, r *http.Request, rules []FaultRule) bool {
+	for _, rule := range rules {
+		if rule.Latency.Duration > 0 {
+			injectedFaults.WithLabelValues(rule.Name, "latency").Inc()
+
+			select {
+			case <-r.Context().Done():
+				return true
+			case <-time.After(rule.Latency.Duration):
+			}
+		}
+
+		if rule.Reset {
+			injectedFaults.WithLabelValues(rule.Name, "reset").Inc()
+			resetConnection(w)
+			return true
+		}
+
+		if rule.Status != 0 {
+			injectedFaults.WithLabelValues(rule.Name, "status").Inc()
+			w.WriteHeader(rule.Status)
+			fmt.Fprintf(w, "error: fault injected by rule %s", rule.Name)
+			return true
+		}
+	}
+
+	return false
+}
+
+// resetConnection closes the client connection with a TCP RST if possible
+func resetConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.SetLinger(0)
+	}
+
+	conn.Close()
+}
+
+// handleAdmin lists (GET), replaces (PUT/POST) or removes (DELETE) the fault rules
+func (f *FaultInjector) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		f.mu.RLock()
+		rules := FaultRules{Rules: f.rules}
+		f.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&rules)
+	case http.MethodPut, http.MethodPost:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "error: %s", err)
+			return
+		}
+
+		rules, err := parseFaultRules(data)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "error: %s", err)
+			return
+		}
+
+		f.SetRules(rules)
+		LOGGER.Info("fault rules updated", zap.Int("rules", len(rules)))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		f.SetRules(nil)
+		LOGGER.Info("fault rules removed")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "Error: Method not allowed")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg/actions"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// jobRetention is how long finished jobs can still be queried
+const jobRetention = time.Hour
+
+var runningJobs = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_running_jobs",
+	Help: "The number of async workloads currently executing",
+})
+
+var finishedJobs = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_finished_jobs_total",
+	Help: "The number of finished async workloads by status",
+}, []string{"status"})
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Job is a workload executed independently of the request that submitted it
+type Job struct {
+	ID       string     `json:"id"`
+	Status   JobStatus  `json:"status"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Duration string     `json:"duration"`
+	Error    string     `json:"error,omitempty"`
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type JobManager struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+var JOBS *JobManager = NewJobManager()
+
+func NewJobManager() *JobManager {
+	return &JobManager{
+		jobs: make(map[string]*Job),
+	}
+}
+
+// Start executes the workload in the background. The job keeps the trace and logger
+// of ctx but not its cancellation, it ends when the workload is done or cancelled, before
+// the next action.
+// release is called once the workload is done.
+func (m *JobManager) Start(ctx context.Context, workload *actions.Workload, release func()) *Job {
+	ctx, cancel := context.WithCancel(actions.WithStopOnCancel(context.WithoutCancel(ctx)))
+
+	job := &Job{
+		ID:      uuid.NewString(),
+		Status:  JobRunning,
+		Started: time.Now(),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	runningJobs.Inc()
+	logger.FromContext(ctx).Info("job started", zap.String("job", job.ID))
+
+	go func() {
+		defer release()
+		defer close(job.done)
+		defer cancel()
+
+		err := workload.Execute(ctx)
+
+		m.mu.Lock()
+		finished := time.Now()
+		job.Finished = &finished
+
+		switch {
+		case err == nil:
+			job.Status = JobSucceeded
+		case ctx.Err() == context.Canceled:
+			job.Status = JobCancelled
+			job.Error = err.Error()
+		default:
+			job.Status = JobFailed
+			job.Error = err.Error()
+		}
+		status := job.Status
+		m.mu.Unlock()
+
+		runningJobs.Dec()
+		finishedJobs.WithLabelValues(string(status)).Inc()
+		logger.FromContext(ctx).Info("job finished", zap.String("job", job.ID), zap.String("status", string(status)), zap.Error(err))
+	}()
+
+	return job
+}
+
+// Get returns a snapshot of the job, or false if it is unknown or expired
+func (m *JobManager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	return job.snapshotLocked(), true
+}
+
+// Cancel cancels the job context and waits for the workload to return
+func (m *JobManager) Cancel(ctx context.Context, id string) (Job, bool) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return Job{}, false
+	}
+
+	job.cancel()
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+	}
+
+	return m.Get(id)
+}
+
+func (m *JobManager) pruneLocked() {
+	for id, job := range m.jobs {
+		if job.Finished != nil && time.Since(*job.Finished) > jobRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func (job *Job) snapshotLocked() Job {
+	snapshot := Job{
+		ID:       job.ID,
+		Status:   job.Status,
+		Started:  job.Started,
+		Finished: job.Finished,
+		Error:    job.Error,
+	}
+
+	if job.Finished != nil {
+		snapshot.Duration = job.Finished.Sub(job.Started).String()
+	} else {
+		snapshot.Duration = time.Since(job.Started).String()
+	}
+
+	return snapshot
+}
+
+func writeJob(w http.ResponseWriter, status int, job Job) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&job)
+}
+
+// handleJob returns (GET) or cancels (DELETE) the job with the id in the path
+func (m *JobManager) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var job Job
+	var ok bool
+
+	switch r.Method {
+	case http.MethodGet:
+		job, ok = m.Get(id)
+	case http.MethodDelete:
+		job, ok = m.Cancel(r.Context(), id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "Error: Method not allowed")
+		return
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "error: job %s not found", id)
+		return
+	}
+
+	writeJob(w, http.StatusOK, job)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var errQueueFull = errors.New("request queue is full")
+var errQueueTimeout = errors.New("timed out waiting in request queue")
+
+var activeWorkloads = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_active_workloads",
+	Help: "The number of workloads currently executing",
+})
+
+var queueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_queue_depth",
+	Help: "The number of requests waiting for a free workload slot",
+})
+
+var queueWaitDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name: "chaosmania_queue_wait_duration",
+	Help: "The time requests waited in the queue before executing",
+})
+
+var shedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_shed_requests_total",
+	Help: "The number of requests rejected by load shedding",
+}, []string{"reason"})
+
+type QueueOrder string
+
+const (
+	QueueFIFO QueueOrder = "fifo"
+	QueueLIFO QueueOrder = "lifo"
+)
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// workloadLimiter bounds the number of concurrently executing workloads, like the
+// thread pool of a real service. Requests above the limit wait in a bounded queue.
+type workloadLimiter struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	queueSize     int
+	order         QueueOrder
+	timeout       time.Duration
+	running       int
+	queue         *list.List
+}
+
+func newWorkloadLimiter(maxConcurrent int, queueSize int, order QueueOrder, timeout time.Duration) *workloadLimiter {
+	return &workloadLimiter{
+		maxConcurrent: maxConcurrent,
+		queueSize:     queueSize,
+		order:         order,
+		timeout:       timeout,
+		queue:         list.New(),
+	}
+}
+
+// Acquire waits for a free workload slot. It fails with errQueueFull or errQueueTimeout
+// if the request is shed, or the context error if the request is cancelled while waiting.
+func (l *workloadLimiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.running < l.maxConcurrent && l.queue.Len() == 0 {
+		l.running++
+		activeWorkloads.Inc()
+		l.mu.Unlock()
+		return nil
+	}
+
+	if l.queue.Len() >= l.queueSize {
+		l.mu.Unlock()
+		shedRequests.WithLabelValues("queue_full").Inc()
+		return errQueueFull
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	elem := l.queue.PushBack(w)
+	queueDepth.Inc()
+	l.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		queueWaitDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		t := time.NewTimer(l.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timeout:
+		err = errQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if w.granted {
+		// The slot was handed over while giving up, pass it on
+		l.releaseLocked()
+	} else {
+		l.queue.Remove(elem)
+		queueDepth.Dec()
+	}
+
+	if err == errQueueTimeout {
+		shedRequests.WithLabelValues("queue_timeout").Inc()
+	}
+
+	return err
+}
+
+// Release frees the slot taken by Acquire, handing it to the next queued request
+func (l *workloadLimiter) Release() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.releaseLocked()
+}
+
+func (l *workloadLimiter) releaseLocked() {
+	var elem *list.Element
+	if l.order == QueueLIFO {
+		elem = l.queue.Back()
+	} else {
+		elem = l.queue.Front()
+	}
+
+	if elem == nil {
+		l.running--
+		activeWorkloads.Dec()
+		return
+	}
+
+	l.queue.Remove(elem)
+	queueDepth.Dec()
+
+	w := elem.Value.(*waiter)
+	w.granted = true
+	close(w.ready)
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"net/http"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/urfave/cli/v2"
 )
 
@@ -14,6 +18,12 @@ func main() {
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	encoder := zapcore.NewConsoleEncoder(encoderConfig)
 	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.InfoLevel)
+
+	if otlpLogsEnabled() {
+		// Write to the global logger provider as well, the core is enabled once initOTLPLogs installs it
+		core = zapcore.NewTee(core, logger.NewOpenTelemetryCore(tracing.InstrumentationName, zapcore.InfoLevel))
+	}
+
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 
 	defer func() {
@@ -22,7 +32,7 @@ func main() {
 
 	app := &cli.App{
 		Name:  "chaosmania",
-		Usage: "chaosmania client|server",
+		Usage: "chaosmania client|server|replay",
 		Commands: []*cli.Command{{
 			Name: "client",
 			Action: func(ctx *cli.Context) error {
@@ -66,6 +76,45 @@ func main() {
 					Usage: "Number of times to repeat each phase (0 for unlimited, max 500)",
 					Value: -1,
 				},
+				&cli.PathFlag{
+					Name:  "record",
+					Usage: "Record every request sent to a JSON lines file, for later replay",
+					Value: "",
+				},
+			},
+		}, {
+			Name:      "replay",
+			Usage:     "Replay a recording made with client --record",
+			ArgsUsage: "<recording.jsonl>",
+			Action: func(ctx *cli.Context) error {
+				return command_replay(logger, ctx)
+			},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "host",
+					Usage:    "Host",
+					Required: true,
+				},
+				&cli.Int64Flag{
+					Name:     "port",
+					Usage:    "Port",
+					Required: true,
+				},
+				&cli.StringSliceFlag{
+					Name:     "header",
+					Usage:    "Headers to include in the request",
+					Required: false,
+				},
+				&cli.Float64Flag{
+					Name:  "speed",
+					Usage: "Time scale for the recorded timing (e.g., 2 replays twice as fast)",
+					Value: 1,
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Client timeout for each replayed request",
+					Value: 10 * time.Second,
+				},
 			},
 		}, {
 			Name: "server",
@@ -78,6 +127,46 @@ func main() {
 					Usage:    "Pod",
 					Required: true,
 				},
+				&cli.IntFlag{
+					Name:  "max-concurrency",
+					Usage: "Maximum number of concurrently executing workloads (0 for unlimited)",
+					Value: 0,
+				},
+				&cli.IntFlag{
+					Name:  "queue-size",
+					Usage: "Number of requests that may wait for a free workload slot, further requests are shed",
+					Value: 0,
+				},
+				&cli.StringFlag{
+					Name:  "queue-order",
+					Usage: "Order in which queued requests are executed (fifo, lifo)",
+					Value: "fifo",
+				},
+				&cli.DurationFlag{
+					Name:  "queue-timeout",
+					Usage: "Maximum time a request waits in the queue before being shed (0 for no timeout)",
+					Value: 0,
+				},
+				&cli.PathFlag{
+					Name:  "endpoints",
+					Usage: "Path to the endpoint definitions (default /etc/chaosmania/endpoints.yaml if it exists)",
+					Value: "",
+				},
+				&cli.PathFlag{
+					Name:  "fault-rules",
+					Usage: "Path to the fault injection rules (default /etc/chaosmania/faults.yaml if it exists)",
+					Value: "",
+				},
+				&cli.IntFlag{
+					Name:  "shed-status-code",
+					Usage: "Status code returned for shed requests (e.g., 503 or 429)",
+					Value: http.StatusServiceUnavailable,
+				},
+				&cli.DurationFlag{
+					Name:  "drain-timeout",
+					Usage: "Maximum time to wait on shutdown for background services to finish handling messages",
+					Value: 30 * time.Second,
+				},
 			},
 		}},
 	}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg/actions"
+)
+
+var errEmulatedFailure = errors.New("emulated network failure")
+
+// networkTransport emulates bad network conditions on top of a dedicated http.Transport
+type networkTransport struct {
+	conditions *actions.NetworkConditions
+	base       http.RoundTripper
+}
+
+// newNetworkTransport wraps the transport to emulate the given network conditions
+func newNetworkTransport(conditions *actions.NetworkConditions, transport *http.Transport) http.RoundTripper {
+	if conditions.Bandwidth > 0 {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			return &throttledConn{Conn: conn, bandwidth: conditions.Bandwidth}, nil
+		}
+	}
+
+	return &networkTransport{
+		conditions: conditions,
+		base:       transport,
+	}
+}
+
+func (t *networkTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.conditions.FailRate > 0 && rand.Float64() < t.conditions.FailRate {
+		return nil, errEmulatedFailure
+	}
+
+	if delay := t.delay(); delay > 0 {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if t.conditions.DropRate > 0 && rand.Float64() < t.conditions.DropRate {
+		// Close the connection once the request is written, before the response arrives
+		var conn net.Conn
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				conn = info.Conn
+			},
+			WroteRequest: func(httptrace.WroteRequestInfo) {
+				if conn != nil {
+					conn.Close()
+				}
+			},
+		}
+
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+func (t *networkTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+func (t *networkTransport) delay() time.Duration {
+	delay := t.conditions.Latency
+	if t.conditions.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(2*t.conditions.Jitter)+1)) - t.conditions.Jitter
+	}
+
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
+
+// throttledConn caps the throughput of a connection in both directions
+type throttledConn struct {
+	net.Conn
+	bandwidth int64
+}
+
+func (c *throttledConn) chunk(n int) int {
+	// Transfer at most 100ms worth of data at once, so throttling stays smooth
+	max := int(c.bandwidth / 10)
+	if max < 1 {
+		max = 1
+	}
+
+	if n > max {
+		return max
+	}
+
+	return n
+}
+
+func (c *throttledConn) wait(n int) {
+	time.Sleep(time.Duration(int64(n) * int64(time.Second) / c.bandwidth))
+}
+
+func (c *throttledConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b[:c.chunk(len(b))])
+	if n > 0 {
+		c.wait(n)
+	}
+
+	return n, err
+}
+
+func (c *throttledConn) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		n, err := c.Conn.Write(b[written : written+c.chunk(len(b)-written)])
+		written += n
+		if n > 0 {
+			c.wait(n)
+		}
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
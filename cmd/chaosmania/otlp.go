@@ -5,15 +5,88 @@ import (
 	"os"
 	"strings"
 
+	chaoslogger "github.com/Causely/chaosmania/pkg/logger"
+	otelprom "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/contrib/propagators/autoprop"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
-	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.uber.org/zap"
 )
 
+// otlpProtocol returns the protocol of a signal, from OTEL_EXPORTER_OTLP_<SIGNAL>_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL.
+// It is either grpc or http/protobuf, the default.
+func otlpProtocol(signal string) string {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	if protocol == "grpc" {
+		return protocol
+	}
+
+	return "http/protobuf"
+}
+
+// otlpEndpoint returns the endpoint of a signal, from OTEL_EXPORTER_OTLP_<SIGNAL>_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT.
+// A signal without an endpoint is not exported.
+func otlpEndpoint(signal string) string {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_ENDPOINT")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	return endpoint
+}
+
+// otlpMetricsEnabled reports whether the metrics are pushed via OTLP
+func otlpMetricsEnabled() bool {
+	return os.Getenv("OTEL_METRICS_EXPORTER") == "otlp" && otlpEndpoint("METRICS") != ""
+}
+
+// otlpLogsEnabled reports whether the logs are exported via OTLP
+func otlpLogsEnabled() bool {
+	return os.Getenv("OTEL_LOGS_EXPORTER") == "otlp" && otlpEndpoint("LOGS") != ""
+}
+
+// The exporters are configured by OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_INSECURE, OTEL_EXPORTER_OTLP_HEADERS
+// and their per signal variants.
+
+func newTraceExporter(ctx context.Context) (trace.SpanExporter, error) {
+	if otlpProtocol("TRACES") == "grpc" {
+		return otlptracegrpc.New(ctx)
+	}
+
+	return otlptracehttp.New(ctx)
+}
+
+func newMetricExporter(ctx context.Context) (sdkmetric.Exporter, error) {
+	if otlpProtocol("METRICS") == "grpc" {
+		return otlpmetricgrpc.New(ctx)
+	}
+
+	return otlpmetrichttp.New(ctx)
+}
+
+func newLogExporter(ctx context.Context) (sdklog.Exporter, error) {
+	if otlpProtocol("LOGS") == "grpc" {
+		return otlploggrpc.New(ctx)
+	}
+
+	return otlploghttp.New(ctx)
+}
+
 func initOTLP(logger *zap.Logger) func() {
 	ctx := context.Background()
 
@@ -33,8 +106,40 @@ func initOTLP(logger *zap.Logger) func() {
 		return func() {}
 	}
 
-	// Set up a trace exporter using OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_INSECURE, OTEL_EXPORTER_OTLP_HEADERS
-	traceExporter, err := otlptracehttp.New(context.Background())
+	// The propagators are read from OTEL_PROPAGATORS, e.g. tracecontext,baggage,b3,
+	// and default to tracecontext and baggage.
+	propagator := autoprop.NewTextMapPropagator()
+	otel.SetTextMapPropagator(propagator)
+
+	shutdownTraces := func() {}
+	if otlpEndpoint("TRACES") != "" {
+		shutdownTraces = initOTLPTraces(logger, res, propagator.Fields())
+	}
+
+	shutdownMetrics := func() {}
+	if otlpMetricsEnabled() {
+		shutdownMetrics = initOTLPMetrics(logger, res)
+	}
+
+	shutdownLogs := func() {}
+	if otlpLogsEnabled() {
+		shutdownLogs = initOTLPLogs(logger, res)
+	}
+
+	return func() {
+		shutdownMetrics()
+		shutdownTraces()
+
+		// Last, so the logs written while shutting down are exported as well
+		shutdownLogs()
+	}
+}
+
+// initOTLPTraces exports the spans via OTLP
+func initOTLPTraces(logger *zap.Logger, res *resource.Resource, propagators []string) func() {
+	ctx := context.Background()
+
+	traceExporter, err := newTraceExporter(ctx)
 	if err != nil {
 		logger.Error("failed to create tracer", zap.Error(err))
 		return func() {}
@@ -42,18 +147,22 @@ func initOTLP(logger *zap.Logger) func() {
 
 	// Register the trace exporter with a TracerProvider,
 	// using a batch span processor to aggregate spans before export.
+	// The sampler is read from OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG,
+	// e.g. parentbased_traceidratio with 0.1, and defaults to parentbased_always_on.
 	batchSpanProcessor := trace.NewBatchSpanProcessor(traceExporter)
 	tracerProvider := trace.NewTracerProvider(
-		trace.WithSampler(trace.AlwaysSample()),
 		trace.WithResource(res),
 		trace.WithSpanProcessor(batchSpanProcessor),
 	)
 	otel.SetTracerProvider(tracerProvider)
 
-	// set global propagator to tracecontext (the default is no-op).
-	otel.SetTextMapPropagator(propagation.TraceContext{})
-
-	logger.Info("OTLP exporter initialized", zap.String("endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")), zap.String("service", os.Getenv("DEPLOYMENT_NAME")))
+	logger.Info("OTLP exporter initialized",
+		zap.String("endpoint", otlpEndpoint("TRACES")),
+		zap.String("protocol", otlpProtocol("TRACES")),
+		zap.String("service", os.Getenv("DEPLOYMENT_NAME")),
+		zap.String("sampler", os.Getenv("OTEL_TRACES_SAMPLER")),
+		zap.Strings("propagators", propagators),
+	)
 
 	return func() {
 		err := tracerProvider.Shutdown(ctx)
@@ -63,16 +172,76 @@ func initOTLP(logger *zap.Logger) func() {
 	}
 }
 
-// Initializes an OTLP exporter, and configures the corresponding trace provider.
+// initOTLPMetrics periodically pushes the metrics of the Prometheus default registry via OTLP
+func initOTLPMetrics(logger *zap.Logger, res *resource.Resource) func() {
+	ctx := context.Background()
+
+	metricExporter, err := newMetricExporter(ctx)
+	if err != nil {
+		logger.Error("failed to create metric exporter", zap.Error(err))
+		return func() {}
+	}
+
+	reader := sdkmetric.NewPeriodicReader(metricExporter,
+		sdkmetric.WithProducer(otelprom.NewMetricProducer()),
+	)
+
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(reader),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	logger.Info("OTLP metric exporter initialized", zap.String("protocol", otlpProtocol("METRICS")))
+
+	return func() {
+		err := meterProvider.Shutdown(ctx)
+		if err != nil {
+			logger.Warn("failed to shutdown meter provider", zap.Error(err))
+		}
+	}
+}
+
+// initOTLPLogs exports the logs written to the OpenTelemetry zap core via OTLP
+func initOTLPLogs(logger *zap.Logger, res *resource.Resource) func() {
+	ctx := context.Background()
+
+	logExporter, err := newLogExporter(ctx)
+	if err != nil {
+		logger.Error("failed to create log exporter", zap.Error(err))
+		return func() {}
+	}
+
+	loggerProvider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+	)
+	global.SetLoggerProvider(loggerProvider)
+	chaoslogger.EnableOpenTelemetry()
+
+	logger.Info("OTLP log exporter initialized", zap.String("protocol", otlpProtocol("LOGS")))
+
+	return func() {
+		err := loggerProvider.Shutdown(ctx)
+		if err != nil {
+			logger.Warn("failed to shutdown logger provider", zap.Error(err))
+		}
+	}
+}
+
+// Initializes the OTLP exporters of the signals with an endpoint, from OTEL_EXPORTER_OTLP_ENDPOINT
+// or the per signal variants.
 func InitOTLPProvider(logger *zap.Logger) func() {
-	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-
-	if endpoint != "" {
-		hostIp := os.Getenv("HOST_IP")
-		endpoint = strings.Replace(endpoint, "$(HOST_IP)", hostIp, 1)
-		os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", endpoint)
-		return initOTLP(logger)
-	} else {
+	hostIp := os.Getenv("HOST_IP")
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"} {
+		if endpoint := os.Getenv(name); endpoint != "" {
+			os.Setenv(name, strings.Replace(endpoint, "$(HOST_IP)", hostIp, 1))
+		}
+	}
+
+	if otlpEndpoint("TRACES") == "" && otlpEndpoint("METRICS") == "" && otlpEndpoint("LOGS") == "" {
 		return func() {}
 	}
+
+	return initOTLP(logger)
 }
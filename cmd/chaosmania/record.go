@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// RecordedRequest is a single line of a traffic recording
+type RecordedRequest struct {
+	Timestamp           time.Time       `json:"timestamp"`
+	Phase               string          `json:"phase"`
+	Worker              string          `json:"worker"`
+	Payload             json.RawMessage `json:"payload"`
+	Status              int             `json:"status"`
+	LatencyMicroseconds int64           `json:"latency_us"`
+	Error               string          `json:"error,omitempty"`
+}
+
+// Recorder writes every request sent by the client to a JSON lines file
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+	return &Recorder{
+		file:    f,
+		writer:  w,
+		encoder: json.NewEncoder(w),
+	}, nil
+}
+
+// Worker returns a recorder bound to a phase and worker, or nil if recording is disabled
+func (r *Recorder) Worker(phase string, worker string) *workerRecorder {
+	if r == nil {
+		return nil
+	}
+
+	return &workerRecorder{
+		recorder: r,
+		phase:    phase,
+		worker:   worker,
+	}
+}
+
+func (r *Recorder) write(entry *RecordedRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.encoder.Encode(entry)
+}
+
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.writer.Flush()
+	if err != nil {
+		r.file.Close()
+		return err
+	}
+
+	return r.file.Close()
+}
+
+type workerRecorder struct {
+	recorder *Recorder
+	phase    string
+	worker   string
+}
+
+// Record logs a request; status is 0 if the request failed before a response was received
+func (w *workerRecorder) Record(start time.Time, payload []byte, status int, took time.Duration, err error) error {
+	if w == nil {
+		return nil
+	}
+
+	entry := RecordedRequest{
+		Timestamp:           start,
+		Phase:               w.phase,
+		Worker:              w.worker,
+		Payload:             payload,
+		Status:              status,
+		LatencyMicroseconds: took.Microseconds(),
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	return w.recorder.write(&entry)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+type replayStatistics struct {
+	mu                           sync.Mutex
+	requests                     uint64
+	errors                       uint64
+	mismatches                   uint64
+	durationMicroseconds         uint64
+	recordedDurationMicroseconds uint64
+	statusCodes                  map[int]int
+}
+
+func replayRequest(logger *zap.Logger, ctx context.Context, stats *replayStatistics, timeout time.Duration, url string, headers map[string]string, entry *RecordedRequest) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(entry.Payload))
+	if err != nil {
+		logger.Error("failed to create request", zap.Error(err))
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		if k == "Host" {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+
+	start := time.Now()
+	resp, err := doRequest(req, &timeout, nil)
+	took := time.Since(start)
+
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	} else if ctx.Err() != nil {
+		// Replay was interrupted, don't count the request
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	stats.requests++
+	if err != nil || isErrorStatus(status) {
+		stats.errors++
+	}
+	if status != entry.Status {
+		stats.mismatches++
+	}
+	stats.durationMicroseconds += uint64(took.Microseconds())
+	stats.recordedDurationMicroseconds += uint64(entry.LatencyMicroseconds)
+	stats.statusCodes[status]++
+}
+
+func command_replay(logger *zap.Logger, ctx *cli.Context) error {
+	path := ctx.Args().First()
+	host := ctx.String("host")
+	port := ctx.Int64("port")
+	header := ctx.StringSlice("header")
+	speed := ctx.Float64("speed")
+	timeout := ctx.Duration("timeout")
+
+	if path == "" {
+		return fmt.Errorf("path to the recording is required")
+	}
+
+	if speed <= 0 {
+		return fmt.Errorf("speed must be greater than 0")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Create headers map
+	headers := make(map[string]string)
+	for _, h := range header {
+		parts := strings.Split(h, ":")
+		headers[parts[0]] = parts[1]
+	}
+
+	shutdown := InitOTLPProvider(logger)
+	defer shutdown()
+
+	// Trace the requests with the backend enabled by the environment
+	tracing.SetProvider(tracing.FromEnvironment())
+
+	rootCtx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	stats := replayStatistics{
+		statusCodes: make(map[int]int),
+	}
+
+	url := fmt.Sprintf("http://%s:%d/", host, port)
+	logger.Info(fmt.Sprintf("Replaying %s against %s at %vx speed", path, url, speed))
+
+	// The recording is written as requests complete, so it is sorted by start time first
+	var entries []*RecordedRequest
+	decoder := json.NewDecoder(f)
+	for {
+		var entry RecordedRequest
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse recording: %w", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	// Each request is sent at its original offset from the first recorded request,
+	// scaled by the speed factor.
+	var wg sync.WaitGroup
+	replayStart := time.Now()
+
+loop:
+	for _, entry := range entries {
+		offset := time.Duration(float64(entry.Timestamp.Sub(entries[0].Timestamp)) / speed)
+		if wait := time.Until(replayStart.Add(offset)); wait > 0 {
+			select {
+			case <-rootCtx.Done():
+				break loop
+			case <-time.After(wait):
+			}
+		}
+
+		if rootCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(entry *RecordedRequest) {
+			defer wg.Done()
+			replayRequest(logger, rootCtx, &stats, timeout, url, headers, entry)
+		}(entry)
+	}
+
+	wg.Wait()
+
+	if rootCtx.Err() != nil {
+		logger.Info("Replay interrupted, stopping")
+	}
+
+	var latency, recordedLatency time.Duration
+	if stats.requests > 0 {
+		latency = time.Duration(int64(stats.durationMicroseconds/stats.requests)) * time.Microsecond
+		recordedLatency = time.Duration(int64(stats.recordedDurationMicroseconds/stats.requests)) * time.Microsecond
+	}
+
+	logger.Info("")
+	logger.Info("Replay complete")
+	logger.Info(fmt.Sprintf("  Duration: %v", time.Since(replayStart)))
+	logger.Info(fmt.Sprintf("  Requests: %v (%v errors)", stats.requests, stats.errors))
+	logger.Info(fmt.Sprintf("  Average request duration: %v (recorded %v)", latency, recordedLatency))
+	logger.Info(fmt.Sprintf("  Status code mismatches: %v", stats.mismatches))
+
+	if len(stats.statusCodes) > 0 {
+		logger.Info("  Status codes:")
+		for code, count := range stats.statusCodes {
+			logger.Info(fmt.Sprintf("    %v: %v", code, count))
+		}
+	}
+
+	return nil
+}
@@ -9,26 +9,30 @@ import (
 	"os"
 	"os/signal"
 	"regexp"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/actions"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli/v2"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
-	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 var LOGGER *zap.Logger
 
+// LIMITER bounds the concurrently executing workloads, nil if unlimited
+var LIMITER *workloadLimiter
+
+// SHED_STATUS_CODE is returned for requests rejected by the limiter
+var SHED_STATUS_CODE = http.StatusServiceUnavailable
+
 var processedTransactionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
 	Name: "chaosmania_processed_transactions_duration",
 	Help: "The processed transactions duration",
@@ -56,59 +60,162 @@ func handleRequests(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), actions.ResponseWriterKey, w)
-		ctx = logger.NewContext(ctx, LOGGER)
+		serveWorkload(w, r, &workload, start, workloadResponse{Body: " "})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "Error: Method not allowed")
+	}
+}
+
+// workloadResponse is written once a workload is executed
+type workloadResponse struct {
+	// Status is written on success, if set and no action wrote one
+	Status int
+	// ErrorStatus is written if the workload failed, defaults to 500
+	ErrorStatus int
+	Body        string
+}
+
+// serveWorkload executes a verified workload for the request and writes the response
+func serveWorkload(w http.ResponseWriter, r *http.Request, workload *actions.Workload, start time.Time, response workloadResponse) {
+	errorStatus := response.ErrorStatus
+	if errorStatus == 0 {
+		errorStatus = http.StatusInternalServerError
+	}
+
+	// Wait for a free workload slot, or shed the request
+	err := LIMITER.Acquire(r.Context())
+	if err != nil {
+		w.WriteHeader(SHED_STATUS_CODE)
+		fmt.Fprintf(w, "error: %s", err)
+		return
+	}
+	release := LIMITER.Release
+	defer func() {
+		if release != nil {
+			release()
+		}
+	}()
 
+	// Random decisions of actions are reproducible with the seed header
+	randCtx := actions.NewRandContext(r.Context(), r.Header)
+
+	if workload.Async {
+		// The job holds on to the workload slot until it is done
+		job := JOBS.Start(logger.NewContext(randCtx, LOGGER), workload, release)
+		release = nil
+
+		snapshot, _ := JOBS.Get(job.ID)
+		writeJob(w, http.StatusAccepted, snapshot)
+		return
+	}
+
+	// Actions like HTTPResponse may write the status code before the workload is done
+	sw := &statusWriter{ResponseWriter: w}
+	w = sw
+
+	ctx := context.WithValue(randCtx, actions.ResponseWriterKey, w)
+	ctx = logger.NewContext(ctx, LOGGER)
+
+	writeStatus := func(status int) {
+		if status != 0 && !sw.wroteHeader() {
+			w.WriteHeader(status)
+		}
+	}
+
+	if r.Header.Get(actions.ExplainHeader) != "" {
+		// Headers have to be set before an HTTPResponse action writes the status code
+		w.Header().Set(actions.ExplainHeader, "true")
+		w.Header().Set("Content-Type", "application/json")
+
+		ctx, tree := actions.NewExplainContext(ctx)
 		err = workload.Execute(ctx)
+		tree.Finish(err)
+
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "workload error: %s", err)
+			writeStatus(errorStatus)
+			_ = json.NewEncoder(w).Encode(tree)
 			return
 		}
 
-		fmt.Fprint(w, " ")
+		writeStatus(response.Status)
+		_ = json.NewEncoder(w).Encode(tree)
 		processedTransactionDuration.Observe(float64(time.Since(start).Seconds()))
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		fmt.Fprintf(w, "Error: Method not allowed")
+		return
 	}
+
+	err = workload.Execute(ctx)
+	if err != nil {
+		writeStatus(errorStatus)
+		fmt.Fprintf(w, "workload error: %s", err)
+		return
+	}
+
+	writeStatus(response.Status)
+	fmt.Fprint(w, response.Body)
+	processedTransactionDuration.Observe(float64(time.Since(start).Seconds()))
 }
 
-func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
+// statusWriter notes whether the status code of the response was written
+type statusWriter struct {
+	http.ResponseWriter
+
+	mu      sync.Mutex
+	written bool
 }
 
-func run(log *zap.Logger, port int64) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", handleRequests)
-	mux.HandleFunc("/health", handleHealth)
-	mux.HandleFunc("/ready", handleHealth)
-	mux.HandleFunc("/debug/pprof/", pprof.Index)
-	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+func (s *statusWriter) WriteHeader(statusCode int) {
+	s.mu.Lock()
+	s.written = true
+	s.mu.Unlock()
 
-	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer,
-		promhttp.HandlerOpts{
-			EnableOpenMetrics: true,
-		},
-	))
+	s.ResponseWriter.WriteHeader(statusCode)
+}
 
-	server := &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: mux}
-	go func() {
-		log.Info(fmt.Sprintf("listening at %v", port))
-		err := server.ListenAndServe()
+func (s *statusWriter) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	s.written = true
+	s.mu.Unlock()
 
-		if err != nil {
-			log.Warn("webserver error", zap.Error(err))
-		}
-	}()
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusWriter) wroteHeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.written
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
 }
 
-func runWithOTEL(log *zap.Logger, port int64) {
+// serverProtocols accepts HTTP/1.1 and unencrypted HTTP/2, so clients can use either
+func serverProtocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return protocols
+}
+
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// newMux routes the built-in endpoints and then the configured ones, it fails if a
+// configured route is invalid or conflicts with another one
+func newMux(endpoints []Endpoint) (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", handleRequests)
 	mux.HandleFunc("/health", handleHealth)
+	mux.HandleFunc("/ready", handleHealth)
+	mux.HandleFunc("/jobs/{id}", JOBS.handleJob)
+	mux.HandleFunc("/admin/faults", FAULTS.handleAdmin)
+	mux.HandleFunc("/admin/actions", handleAdminActions)
+	mux.HandleFunc("/admin/services", handleAdminServices)
+	mux.HandleFunc("/admin/background", handleAdminBackground)
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
@@ -120,49 +227,29 @@ func runWithOTEL(log *zap.Logger, port int64) {
 		},
 	))
 
-	handler := otelhttp.NewHandler(mux, "server",
-		otelhttp.WithSpanNameFormatter(func(operation string, req *http.Request) string {
-			return req.URL.Path
-		}),
-		otelhttp.WithFilter(func(req *http.Request) bool {
-			return req.URL.Path != "/health" &&
-				req.URL.Path != "/metrics" &&
-				req.URL.Path != "/debug/pprof/" &&
-				req.URL.Path != "/debug/pprof/profile" &&
-				req.URL.Path != "/debug/pprof/symbol" &&
-				req.URL.Path != "/debug/pprof/trace"
-		}),
-		otelhttp.WithTracerProvider(otel.GetTracerProvider()),
-		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
-	)
-
-	server := &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: handler}
-	go func() {
-		log.Info(fmt.Sprintf("listening at %v", port))
-		err := server.ListenAndServe()
+	err := registerEndpoints(mux, endpoints)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			log.Warn("webserver error", zap.Error(err))
-		}
-	}()
+	return mux, nil
 }
 
-func runWithDatadog(log *zap.Logger, port int64) {
-	mux := httptrace.NewServeMux()
-	mux.HandleFunc("/", handleRequests)
-	mux.HandleFunc("/health", handleHealth)
-	mux.HandleFunc("/debug/pprof/", pprof.Index)
-	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+func run(log *zap.Logger, port int64) error {
+	mux, err := newMux(ENDPOINTS)
+	if err != nil {
+		return err
+	}
 
-	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer,
-		promhttp.HandlerOpts{
-			EnableOpenMetrics: true,
-		},
-	))
+	// Health checks, metrics and profiling are not traced
+	handler := tracing.Get().WrapHandler(FAULTS.Wrap(mux), func(req *http.Request) bool {
+		return req.URL.Path == "/health" ||
+			req.URL.Path == "/ready" ||
+			req.URL.Path == "/metrics" ||
+			strings.HasPrefix(req.URL.Path, "/debug/pprof/")
+	})
 
-	server := &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: mux}
+	server := &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: handler, Protocols: serverProtocols()}
 	go func() {
 		log.Info(fmt.Sprintf("listening at %v", port))
 		err := server.ListenAndServe()
@@ -171,6 +258,8 @@ func runWithDatadog(log *zap.Logger, port int64) {
 			log.Warn("webserver error", zap.Error(err))
 		}
 	}()
+
+	return nil
 }
 
 func fileExists(filepath string) bool {
@@ -198,6 +287,10 @@ func command_server(log *zap.Logger, ctx *cli.Context) error {
 		close(stop)
 	}()
 
+	// Select the tracing provider before services instrument their clients
+	shutdownTracing := initTracing(log)
+	defer shutdownTracing()
+
 	// Load services if any
 	if fileExists("/etc/chaosmania/services.yaml") {
 		err := actions.Manager.LoadFromFile("/etc/chaosmania/services.yaml")
@@ -209,6 +302,36 @@ func command_server(log *zap.Logger, ctx *cli.Context) error {
 		log.Warn("no services.yaml found, not loading any services")
 	}
 
+	// Load fault rules if any
+	if faultRules := ctx.Path("fault-rules"); faultRules != "" || fileExists("/etc/chaosmania/faults.yaml") {
+		if faultRules == "" {
+			faultRules = "/etc/chaosmania/faults.yaml"
+		}
+
+		err := FAULTS.LoadFromFile(faultRules)
+		if err != nil {
+			log.Warn("failed to load fault rules", zap.Error(err))
+			return err
+		}
+
+		log.Info(fmt.Sprintf("loaded fault rules from %s", faultRules))
+	}
+
+	// Load endpoints if any
+	if endpoints := ctx.Path("endpoints"); endpoints != "" || fileExists("/etc/chaosmania/endpoints.yaml") {
+		if endpoints == "" {
+			endpoints = "/etc/chaosmania/endpoints.yaml"
+		}
+
+		err := LoadEndpointsFromFile(endpoints)
+		if err != nil {
+			log.Warn("failed to load endpoints", zap.Error(err))
+			return err
+		}
+
+		log.Info(fmt.Sprintf("loaded %d endpoints from %s", len(ENDPOINTS), endpoints))
+	}
+
 	// Load background services if any
 	if fileExists("/etc/chaosmania/background_services.yaml") {
 		err := actions.BackgroundManager.LoadFromFile("/etc/chaosmania/background_services.yaml")
@@ -233,21 +356,38 @@ func command_server(log *zap.Logger, ctx *cli.Context) error {
 
 	port := ctx.Int64("port")
 
-	if pkg.IsDatadogEnabled() {
-		tracer.Start()
-		defer tracer.Stop()
+	if maxConcurrency := ctx.Int("max-concurrency"); maxConcurrency > 0 {
+		order := QueueOrder(ctx.String("queue-order"))
+		if order != QueueFIFO && order != QueueLIFO {
+			return fmt.Errorf("invalid queue order: %s. Must be one of: fifo, lifo", order)
+		}
 
-		runWithDatadog(log, port)
-	} else if pkg.IsOpenTelemetryEnabled() {
-		shutdown := InitOTLPProvider(log)
-		defer shutdown()
+		shedStatusCode := ctx.Int("shed-status-code")
+		if shedStatusCode < 100 || shedStatusCode > 599 {
+			return fmt.Errorf("invalid shed status code: %d. Must be between 100 and 599", shedStatusCode)
+		}
 
-		runWithOTEL(log, port)
-	} else {
-		run(log, port)
+		SHED_STATUS_CODE = shedStatusCode
+		LIMITER = newWorkloadLimiter(maxConcurrency, ctx.Int("queue-size"), order, ctx.Duration("queue-timeout"))
+		log.Info(fmt.Sprintf("limiting to %d concurrent workloads, queue size %d (%s), queue timeout %v, shedding with %d",
+			maxConcurrency, ctx.Int("queue-size"), order, ctx.Duration("queue-timeout"), SHED_STATUS_CODE))
+	}
+
+	err := run(log, port)
+	if err != nil {
+		log.Warn("failed to start webserver", zap.Error(err))
+		return err
 	}
 
 	<-stop
 
+	// Drain the background services before the tracer is shut down
+	drainTimeout := ctx.Duration("drain-timeout")
+	log.Info("stopping background services", zap.Duration("timeout", drainTimeout))
+	err = actions.BackgroundManager.Stop(drainTimeout)
+	if err != nil {
+		log.Warn("failed to drain background services", zap.Error(err))
+	}
+
 	return nil
 }
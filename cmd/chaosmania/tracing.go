@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"go.uber.org/zap"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// initTracing selects the tracing provider enabled by the environment and starts its backend
+func initTracing(log *zap.Logger) func() {
+	provider := tracing.FromEnvironment()
+	tracing.SetProvider(provider)
+	log.Info("tracing provider selected", zap.String("provider", provider.Name()))
+
+	switch provider.(type) {
+	case tracing.Datadog:
+		tracer.Start()
+		return tracer.Stop
+	case *tracing.OpenTelemetry:
+		return InitOTLPProvider(log)
+	}
+
+	return func() {}
+}
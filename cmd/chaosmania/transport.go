@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/Causely/chaosmania/pkg/actions"
+)
+
+// newWorkerTransport builds the transport for a worker group from its transport settings
+// and network conditions, or returns nil if there are none so the default transport is used.
+func newWorkerTransport(workers *actions.Workers) http.RoundTripper {
+	if workers.Network == nil && workers.Transport == nil {
+		return nil
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	var transport http.RoundTripper = base
+
+	settings := workers.Transport
+	if settings != nil {
+		base.DisableKeepAlives = settings.DisableKeepAlives
+
+		if settings.MaxIdleConns > 0 {
+			base.MaxIdleConns = settings.MaxIdleConns
+		}
+
+		if settings.MaxIdleConnsPerHost > 0 {
+			base.MaxIdleConnsPerHost = settings.MaxIdleConnsPerHost
+		}
+
+		// Requests go to plain http:// URLs, so HTTP/2 means HTTP/2 with prior knowledge (h2c)
+		base.Protocols = new(http.Protocols)
+		if settings.HTTP2 {
+			base.Protocols.SetUnencryptedHTTP2(true)
+		} else {
+			base.Protocols.SetHTTP1(true)
+		}
+	}
+
+	if workers.Network != nil {
+		transport = newNetworkTransport(workers.Network, base)
+	}
+
+	if settings != nil && settings.NewConnectionEvery > 0 {
+		transport = &churnTransport{
+			base:  transport,
+			every: uint64(settings.NewConnectionEvery),
+		}
+	}
+
+	return transport
+}
+
+// churnTransport closes the connection of every Nth request once it is done, so a new one has
+// to be opened. Closing idle connections instead would miss those kept busy by concurrent workers.
+type churnTransport struct {
+	base     http.RoundTripper
+	every    uint64
+	requests atomic.Uint64
+}
+
+func (t *churnTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if n := t.requests.Add(1); n%t.every == 0 {
+		req = req.Clone(req.Context())
+		req.Close = true
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+func (t *churnTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+func closeIdleConnections(transport http.RoundTripper) {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+
+	if c, ok := transport.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+}
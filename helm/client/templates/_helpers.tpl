@@ -77,5 +77,9 @@ Create the name of the service account to use
 - name: OTEL_EXPORTER_OTLP_HEADERS
   value: {{ .Values.otlp.headers | quote }}
 {{- end }}
+{{- if .Values.otlp.metrics }}
+- name: OTEL_METRICS_EXPORTER
+  value: "otlp"
+{{- end }}
 {{- end }}
 {{ end -}}
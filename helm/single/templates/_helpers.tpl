@@ -83,6 +83,30 @@ Create the name of the service account to use
 - name: OTEL_EXPORTER_OTLP_HEADERS
   value: {{ .Values.otlp.headers | quote }}
 {{- end }}
+{{- if .Values.otlp.protocol }}
+- name: OTEL_EXPORTER_OTLP_PROTOCOL
+  value: {{ .Values.otlp.protocol | quote }}
+{{- end }}
+{{- if .Values.otlp.sampler }}
+- name: OTEL_TRACES_SAMPLER
+  value: {{ .Values.otlp.sampler | quote }}
+{{- end }}
+{{- if .Values.otlp.samplerArg }}
+- name: OTEL_TRACES_SAMPLER_ARG
+  value: {{ .Values.otlp.samplerArg | quote }}
+{{- end }}
+{{- if .Values.otlp.propagators }}
+- name: OTEL_PROPAGATORS
+  value: {{ .Values.otlp.propagators | quote }}
+{{- end }}
+{{- if .Values.otlp.metrics }}
+- name: OTEL_METRICS_EXPORTER
+  value: "otlp"
+{{- end }}
+{{- if .Values.otlp.logs }}
+- name: OTEL_LOGS_EXPORTER
+  value: "otlp"
+{{- end }}
 {{- end }}
 {{ end -}}
 
@@ -117,6 +141,9 @@ Create the name of the service account to use
     - "server"
     - "--port"
     - "8080"
+    {{- range .Values.extraArgs }}
+    - {{ . | quote }}
+    {{- end }}
   ports:
     - name: http
       containerPort: 8080
@@ -0,0 +1,302 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// secretKeys are the parts of config keys whose values are redacted
+var secretKeys = []string{"password", "secret", "token", "credential", "accesskey", "apikey"}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactConfig returns a copy of config without secrets: the values of secret keys
+// and the passwords of URLs are replaced.
+func RedactConfig(config map[string]any) map[string]any {
+	if config == nil {
+		return nil
+	}
+
+	redactedConfig := make(map[string]any, len(config))
+	for k, v := range config {
+		if isSecretKey(k) {
+			redactedConfig[k] = redacted
+			continue
+		}
+
+		redactedConfig[k] = redactValue(v)
+	}
+
+	return redactedConfig
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return RedactConfig(v)
+	case map[any]any:
+		// yaml.v2 decodes nested maps with interface keys
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = value
+		}
+		return RedactConfig(m)
+	case []any:
+		values := make([]any, len(v))
+		for i, value := range v {
+			values[i] = redactValue(value)
+		}
+		return values
+	case string:
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// HealthChecker is implemented by services that can check the connection to their dependency
+type HealthChecker interface {
+	Health(context.Context) error
+}
+
+// ServiceStats counts the work done by a background service and keeps its last error
+type ServiceStats struct {
+	mu          sync.Mutex
+	counters    map[string]uint64
+	lastError   string
+	lastErrorAt *time.Time
+}
+
+func (s *ServiceStats) Inc(counter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counters == nil {
+		s.counters = make(map[string]uint64)
+	}
+	s.counters[counter]++
+}
+
+func (s *ServiceStats) SetError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.lastError = err.Error()
+	s.lastErrorAt = &now
+}
+
+func (s *ServiceStats) Stats() *ServiceStats {
+	return s
+}
+
+// StatsReporter is implemented by background services embedding ServiceStats
+type StatsReporter interface {
+	Stats() *ServiceStats
+}
+
+type ActionInfo struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Executions uint64 `json:"executions"`
+	Errors     uint64 `json:"errors"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+type actionStats struct {
+	executions uint64
+	errors     uint64
+	lastError  string
+}
+
+var (
+	actionStatsMu sync.Mutex
+	actionCounts  = make(map[string]*actionStats)
+)
+
+func recordAction(name string, err error) {
+	actionStatsMu.Lock()
+	defer actionStatsMu.Unlock()
+
+	stats, ok := actionCounts[name]
+	if !ok {
+		stats = &actionStats{}
+		actionCounts[name] = stats
+	}
+
+	stats.executions++
+	if err != nil {
+		stats.errors++
+		stats.lastError = err.Error()
+	}
+}
+
+// ActionInfos describes the registered actions, sorted by name
+func ActionInfos() []ActionInfo {
+	actionStatsMu.Lock()
+	defer actionStatsMu.Unlock()
+
+	infos := make([]ActionInfo, 0, len(ACTIONS))
+	for name, action := range ACTIONS {
+		info := ActionInfo{
+			Name: name,
+			Type: fmt.Sprintf("%T", action),
+		}
+
+		if stats, ok := actionCounts[name]; ok {
+			info.Executions = stats.executions
+			info.Errors = stats.errors
+			info.LastError = stats.lastError
+		}
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+type ServiceInfo struct {
+	Name   ServiceName    `json:"name"`
+	Type   ServiceType    `json:"type"`
+	Config map[string]any `json:"config"`
+	// Health is ok or failing for services implementing HealthChecker, unknown otherwise
+	Health string `json:"health"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthCheckTimeout bounds all health checks of a request, they run concurrently
+const healthCheckTimeout = 2 * time.Second
+
+// Info describes the registered services, checking the health of each
+func (sm *ServiceManager) Info(ctx context.Context) []ServiceInfo {
+	infos := make([]ServiceInfo, 0, len(sm.services))
+	services := make([]Service, 0, len(sm.services))
+	for name, service := range sm.services {
+		infos = append(infos, ServiceInfo{
+			Name:   name,
+			Type:   service.Type(),
+			Config: RedactConfig(sm.configs[name]),
+			Health: "unknown",
+		})
+		services = append(services, service)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, service := range services {
+		checker, ok := service.(HealthChecker)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := checker.Health(checkCtx)
+			if err != nil {
+				infos[i].Health = "failing"
+				infos[i].Error = err.Error()
+			} else {
+				infos[i].Health = "ok"
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+type BackgroundServiceInfo struct {
+	Name        BackgroundServiceName `json:"name"`
+	Type        BackgroundServiceType `json:"type"`
+	Config      map[string]any        `json:"config"`
+	Status      string                `json:"status"`
+	Restart     RestartPolicy         `json:"restart"`
+	Restarts    int                   `json:"restarts"`
+	Started     *time.Time            `json:"started,omitempty"`
+	LastError   string                `json:"last_error,omitempty"`
+	LastErrorAt *time.Time            `json:"last_error_at,omitempty"`
+	Counters    map[string]uint64     `json:"counters"`
+}
+
+// Info describes the registered background services
+func (bsm *BackgroundServiceManager) Info() []BackgroundServiceInfo {
+	infos := make([]BackgroundServiceInfo, 0, len(bsm.services))
+	for _, managed := range bsm.services {
+		infos = append(infos, managed.info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+func (m *ManagedBackgroundService) info() BackgroundServiceInfo {
+	m.mu.Lock()
+	info := BackgroundServiceInfo{
+		Name:        m.Service.Name(),
+		Type:        m.Service.Type(),
+		Config:      RedactConfig(m.Config),
+		Status:      m.status,
+		Restart:     m.Restart,
+		Restarts:    m.restarts,
+		Started:     m.started,
+		LastError:   m.lastError,
+		LastErrorAt: m.lastErrorAt,
+		Counters:    map[string]uint64{},
+	}
+	m.mu.Unlock()
+
+	if reporter, ok := m.Service.(StatsReporter); ok {
+		stats := reporter.Stats()
+		stats.mu.Lock()
+		for k, v := range stats.counters {
+			info.Counters[k] = v
+		}
+
+		// Report the latest error, whether the service failed with it or handled it itself
+		if stats.lastErrorAt != nil && (info.LastErrorAt == nil || stats.lastErrorAt.After(*info.LastErrorAt)) {
+			info.LastError = stats.lastError
+			info.LastErrorAt = stats.lastErrorAt
+		}
+		stats.mu.Unlock()
+	}
+
+	return info
+}
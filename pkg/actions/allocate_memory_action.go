@@ -2,59 +2,193 @@ package actions
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
 )
 
-type AllocateMemory struct {
-	mu             sync.Mutex
-	leakedData     [][]byte
-	leakedDataSize int
+const (
+	LeakLimitPlateau = "plateau"
+	LeakLimitRelease = "release"
+)
+
+// leakInterval is how often memory is leaked at the leak rate
+const leakInterval = time.Second
+
+// memoryLeak holds the memory leaked by AllocateMemory until ReleaseMemory frees it
+type memoryLeak struct {
+	mu   sync.Mutex
+	data [][]byte
+	size int
+	// stop ends the growth at the leak rate, nil while memory is not growing
+	stop chan struct{}
 }
 
+var LEAKED_MEMORY = &memoryLeak{}
+
+type AllocateMemory struct{}
+
 type AllocateMemoryConfig struct {
 	SizeBytes      int  `json:"sizeBytes"`
 	NumAllocations int  `json:"numAllocations"`
 	Leak           bool `json:"leak"`
 	LeakLimitBytes int  `json:"leakLimitBytes"`
+	// LeakBytesPerMinute grows the leaked memory in the background at this rate, until
+	// ReleaseMemory. Further calls while memory is growing do not change the rate.
+	LeakBytesPerMinute int `json:"leakBytesPerMinute"`
+	// OnLeakLimit is what happens once growing memory reaches LeakLimitBytes: plateau
+	// stops the growth, release frees the memory and grows again
+	OnLeakLimit string `json:"onLeakLimit"`
 }
 
-func (a *AllocateMemory) leak(config *AllocateMemoryConfig, data [][]byte) {
+func (l *memoryLeak) leak(config *AllocateMemoryConfig, data [][]byte) {
 	if !config.Leak {
 		return
 	}
 
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
 	for i := range data {
-		if config.LeakLimitBytes > 0 && a.leakedDataSize > config.LeakLimitBytes {
+		if config.LeakLimitBytes > 0 && l.size > config.LeakLimitBytes {
 			break
 		}
 
-		a.leakedDataSize += len(data[i])
-		a.leakedData = append(a.leakedData, data[i])
+		l.size += len(data[i])
+		l.data = append(l.data, data[i])
+	}
+
+	leakedMemoryBytes.Set(float64(l.size))
+}
+
+// Release frees the leaked memory, stops its growth and returns the number of bytes freed
+func (l *memoryLeak) Release() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
 	}
+
+	return l.free()
+}
+
+// free drops the leaked memory. Callers must hold the lock.
+func (l *memoryLeak) free() int {
+	freed := l.size
+	l.data = nil
+	l.size = 0
+	leakedMemoryBytes.Set(0)
+
+	return freed
+}
+
+// startGrowing leaks memory at the leak rate in the background, unless it is growing already
+func (l *memoryLeak) startGrowing(ctx context.Context, config *AllocateMemoryConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stop != nil {
+		return
+	}
+
+	l.stop = make(chan struct{})
+	go l.grow(ctx, config, l.stop)
+}
+
+func (l *memoryLeak) grow(ctx context.Context, config *AllocateMemoryConfig, stop chan struct{}) {
+	ticker := time.NewTicker(leakInterval)
+	defer ticker.Stop()
+
+	// due is the number of bytes owed at the leak rate, scaled by a minute so rates below
+	// a byte per interval are carried over between ticks instead of rounded down to 0
+	var due int64
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		due += int64(config.LeakBytesPerMinute) * int64(leakInterval)
+		chunk := int(due / int64(time.Minute))
+		if chunk == 0 {
+			continue
+		}
+		due -= int64(chunk) * int64(time.Minute)
+
+		// Don't grow past the limit
+		size := chunk
+		if config.LeakLimitBytes > 0 {
+			l.mu.Lock()
+			size = max(min(chunk, config.LeakLimitBytes-l.size), 0)
+			l.mu.Unlock()
+		}
+
+		data := allocate(size)
+
+		l.mu.Lock()
+		// ReleaseMemory may have stopped the growth while allocating
+		select {
+		case <-stop:
+			l.mu.Unlock()
+			return
+		default:
+		}
+
+		l.size += len(data)
+		l.data = append(l.data, data)
+		leakedMemoryBytes.Set(float64(l.size))
+
+		if config.LeakLimitBytes > 0 && l.size >= config.LeakLimitBytes {
+			if config.OnLeakLimit == LeakLimitRelease {
+				freed := l.free()
+				logger.FromContext(ctx).Info("leak limit reached, released memory", zap.Int("bytes", freed))
+			} else {
+				l.stop = nil
+				l.mu.Unlock()
+				logger.FromContext(ctx).Info("leak limit reached, memory stopped growing", zap.Int("bytes", config.LeakLimitBytes))
+				return
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// allocate returns size bytes, written so the memory is resident
+func allocate(size int) []byte {
+	d := make([]byte, size)
+	for j := 0; j < size; j++ {
+		d[j] = 1
+	}
+
+	return d
 }
 
 func (a *AllocateMemory) Execute(ctx context.Context, cfg map[string]any) error {
-	config, err := pkg.ParseConfig[AllocateMemoryConfig](cfg)
+	config, err := a.parseConfig(cfg)
 	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
 		return err
 	}
 
 	data := make([][]byte, 0)
 	for i := 0; i < config.NumAllocations; i++ {
-		d := make([]byte, config.SizeBytes)
-		for j := 0; j < config.SizeBytes; j++ {
-			d[j] = 1
-		}
-
-		data = append(data, d)
+		data = append(data, allocate(config.SizeBytes))
 	}
 
-	a.leak(config, data)
+	LEAKED_MEMORY.leak(config, data)
+
+	if config.LeakBytesPerMinute > 0 {
+		// The growth outlives the request
+		LEAKED_MEMORY.startGrowing(context.WithoutCancel(ctx), config)
+	}
 
 	// Make the compiler happy and use the variables
 	var sizeTotal int
@@ -65,8 +199,31 @@ func (a *AllocateMemory) Execute(ctx context.Context, cfg map[string]any) error
 	return nil
 }
 
+func (a *AllocateMemory) parseConfig(data map[string]any) (*AllocateMemoryConfig, error) {
+	config, err := pkg.ParseConfig[AllocateMemoryConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.OnLeakLimit == "" {
+		config.OnLeakLimit = LeakLimitPlateau
+	}
+
+	switch config.OnLeakLimit {
+	case LeakLimitPlateau, LeakLimitRelease:
+	default:
+		return nil, fmt.Errorf("invalid onLeakLimit: %s. Must be one of: %s, %s", config.OnLeakLimit, LeakLimitPlateau, LeakLimitRelease)
+	}
+
+	if config.LeakBytesPerMinute < 0 {
+		return nil, fmt.Errorf("leakBytesPerMinute must not be negative")
+	}
+
+	return config, nil
+}
+
 func (a *AllocateMemory) ParseConfig(data map[string]any) (any, error) {
-	return pkg.ParseConfig[AllocateMemoryConfig](data)
+	return a.parseConfig(data)
 }
 
 func init() {
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -25,9 +27,11 @@ type BackgroundService interface {
 }
 
 type BackgroundServiceDeclaration struct {
-	Name   BackgroundServiceName `yaml:"name"`
-	Type   BackgroundServiceType `yaml:"type"`
-	Config map[string]any        `yaml:"config"`
+	Name    BackgroundServiceName `yaml:"name"`
+	Type    BackgroundServiceType `yaml:"type"`
+	Restart RestartPolicy         `yaml:"restart"`
+	Backoff Backoff               `yaml:"backoff"`
+	Config  map[string]any        `yaml:"config"`
 }
 
 type BackgroundServices struct {
@@ -36,11 +40,32 @@ type BackgroundServices struct {
 
 type ManagedBackgroundService struct {
 	Service BackgroundService
+	// Config is the declared config of the service, if loaded from a file
+	Config  map[string]any
+	Restart RestartPolicy
+	Backoff Backoff
+
+	mu          sync.Mutex
+	status      string
+	started     *time.Time
+	restarts    int
+	lastError   string
+	lastErrorAt *time.Time
 }
 
+const (
+	BackgroundServiceRegistered = "registered"
+	BackgroundServiceRunning    = "running"
+	BackgroundServiceRestarting = "restarting"
+	BackgroundServiceStopped    = "stopped"
+	BackgroundServiceFailed     = "failed"
+)
+
 type BackgroundServiceManager struct {
 	services map[BackgroundServiceName]*ManagedBackgroundService
 	context  context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
 }
 
 func NewBackgroundServiceManager() *BackgroundServiceManager {
@@ -49,20 +74,47 @@ func NewBackgroundServiceManager() *BackgroundServiceManager {
 	}
 }
 
+// Run supervises the services until ctx is cancelled or Stop is called
 func (bsm *BackgroundServiceManager) Run(ctx context.Context) {
-	bsm.context = ctx
+	bsm.context, bsm.cancel = context.WithCancel(ctx)
 
 	for _, service := range bsm.services {
+		bsm.wg.Add(1)
 		go func(service *ManagedBackgroundService) {
-			err := service.Service.Run(ctx)
-			if err != nil {
-				fmt.Println("service", service.Service.Name(), "failed to run:", err)
-			}
+			defer bsm.wg.Done()
+			service.supervise(bsm.context)
 		}(service)
 	}
 }
 
+// Stop cancels the services and waits up to timeout for them to finish the messages they are handling
+func (bsm *BackgroundServiceManager) Stop(timeout time.Duration) error {
+	if bsm.cancel == nil {
+		return nil
+	}
+
+	bsm.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		bsm.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("background services did not stop within %v", timeout)
+	}
+}
+
+// Register registers a service that is restarted on failure with the default backoff
 func (bsm *BackgroundServiceManager) Register(s BackgroundService) error {
+	return bsm.register(s, BackgroundServiceDeclaration{Restart: RestartOnFailure})
+}
+
+func (bsm *BackgroundServiceManager) register(s BackgroundService, declaration BackgroundServiceDeclaration) error {
 	if _, ok := bsm.services[s.Name()]; ok {
 		return errors.New("service already registered")
 	}
@@ -70,6 +122,10 @@ func (bsm *BackgroundServiceManager) Register(s BackgroundService) error {
 	fmt.Println("Registering background service", s.Name(), "of type", s.Type())
 	bsm.services[s.Name()] = &ManagedBackgroundService{
 		Service: s,
+		Config:  declaration.Config,
+		Restart: declaration.Restart,
+		Backoff: declaration.Backoff,
+		status:  BackgroundServiceRegistered,
 	}
 
 	return nil
@@ -107,8 +163,22 @@ func (bsm *BackgroundServiceManager) LoadFromFile(path string) error {
 			return errors.New("background service type not found: " + string(service.Type))
 		}
 
+		if service.Restart == "" {
+			service.Restart = RestartOnFailure
+		}
+
+		err := service.Restart.Verify()
+		if err != nil {
+			return err
+		}
+
+		err = service.Backoff.Verify()
+		if err != nil {
+			return err
+		}
+
 		s := constructor(service.Name, service.Config)
-		err := bsm.Register(s)
+		err = bsm.register(s, service)
 		if err != nil {
 			return err
 		}
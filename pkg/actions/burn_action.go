@@ -23,7 +23,12 @@ func (a *Burn) Execute(ctx context.Context, cfg map[string]any) error {
 	}
 
 	end := time.Now().Add(config.Duration.Duration)
-	for time.Now().Before(end) {
+	for i := 0; time.Now().Before(end); i++ {
+		// Check for cancellation only now and then to keep the loop hot. Like workloads,
+		// only jobs and Timeout stop early, a request whose client is gone burns on.
+		if i%100000 == 0 && stopsOnCancel(ctx) && ctx.Err() != nil {
+			return ctx.Err()
+		}
 	}
 
 	return nil
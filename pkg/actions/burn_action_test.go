@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBurnCancelledRequest(t *testing.T) {
+	// A client that disconnected cancels the request context
+	ctx, cancel := context.WithCancel(testContext())
+	cancel()
+
+	start := time.Now()
+	err := ACTIONS["Burn"].Execute(ctx, map[string]any{"duration": "50ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if took := time.Since(start); took < 50*time.Millisecond {
+		t.Errorf("expected to burn 50ms, burned %v", took)
+	}
+}
+
+func TestBurnStopOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithStopOnCancel(testContext()))
+	cancel()
+
+	start := time.Now()
+	err := ACTIONS["Burn"].Execute(ctx, map[string]any{"duration": "5s"})
+	if err != context.Canceled {
+		t.Errorf("expected the burn to be cancelled, got %v", err)
+	}
+
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("expected the burn to stop, burned %v", took)
+	}
+}
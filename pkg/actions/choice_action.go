@@ -0,0 +1,92 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Choice executes one of its branches, picked at random by weight with the RNG of the request
+type Choice struct{}
+
+type ChoiceBranch struct {
+	Weight  float64        `json:"weight"`
+	Actions []ActionConfig `json:"actions"`
+}
+
+type ChoiceConfig struct {
+	Branches []ChoiceBranch `json:"branches"`
+}
+
+func (a *Choice) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	total := 0.0
+	for _, branch := range config.Branches {
+		total += branch.Weight
+	}
+
+	// Branches without weight are never picked, the last one with weight takes the rounding errors
+	chosen := 0
+	pick := RandFromContext(ctx).Float64() * total
+	for i, branch := range config.Branches {
+		if branch.Weight == 0 {
+			continue
+		}
+
+		chosen = i
+		if pick < branch.Weight {
+			break
+		}
+		pick -= branch.Weight
+	}
+
+	ctx, done := explainAction(ctx, fmt.Sprintf("branch %d", chosen+1))
+	workload := Workload{Actions: config.Branches[chosen].Actions}
+	err = workload.Execute(ctx)
+	done(err)
+
+	return err
+}
+
+func (a *Choice) parseConfig(data map[string]any) (*ChoiceConfig, error) {
+	config, err := pkg.ParseConfig[ChoiceConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	for i, branch := range config.Branches {
+		if branch.Weight < 0 {
+			return nil, fmt.Errorf("branch %d: weight must not be negative", i+1)
+		}
+		total += branch.Weight
+
+		workload := Workload{Actions: branch.Actions}
+		err := workload.Verify()
+		if err != nil {
+			return nil, fmt.Errorf("branch %d: %w", i+1, err)
+		}
+	}
+
+	if total == 0 {
+		return nil, fmt.Errorf("at least one branch with a weight is required")
+	}
+
+	return config, nil
+}
+
+func (a *Choice) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["Choice"] = &Choice{}
+}
@@ -0,0 +1,238 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+var CIRCUIT_BREAKERS_MUTEX sync.Mutex
+var CIRCUIT_BREAKERS map[string]*circuitBreaker = make(map[string]*circuitBreaker)
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// circuitStateValues are the values of the state gauge
+var circuitStateValues = map[CircuitState]float64{
+	CircuitClosed:   0,
+	CircuitOpen:     1,
+	CircuitHalfOpen: 2,
+}
+
+// CircuitOpenError is returned instead of executing the actions while the breaker is open
+type CircuitOpenError struct {
+	Name  string
+	State CircuitState
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %s is %s", e.Name, e.State)
+}
+
+// CircuitBreaker stops executing its actions after they failed repeatedly, like a client
+// protecting itself from a failing dependency. The state is shared by all requests using the
+// same name: after FailureThreshold consecutive failures the breaker opens and fails fast for
+// OpenDuration, then lets HalfOpenProbes requests through and closes once they all succeeded.
+type CircuitBreaker struct{}
+
+type CircuitBreakerConfig struct {
+	Name             string         `json:"name"`
+	FailureThreshold int            `json:"failure_threshold"`
+	OpenDuration     pkg.Duration   `json:"open_duration"`
+	HalfOpenProbes   int            `json:"half_open_probes"`
+	Actions          []ActionConfig `json:"actions"`
+}
+
+type circuitBreaker struct {
+	mu       sync.Mutex
+	name     string
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// probes is the number of requests let through while half-open, successes the ones that succeeded
+	probes    int
+	successes int
+}
+
+func getCircuitBreaker(name string) *circuitBreaker {
+	CIRCUIT_BREAKERS_MUTEX.Lock()
+	defer CIRCUIT_BREAKERS_MUTEX.Unlock()
+
+	breaker, ok := CIRCUIT_BREAKERS[name]
+	if !ok {
+		breaker = &circuitBreaker{name: name, state: CircuitClosed}
+		CIRCUIT_BREAKERS[name] = breaker
+		circuitBreakerState.WithLabelValues(name).Set(circuitStateValues[CircuitClosed])
+	}
+
+	return breaker
+}
+
+// transition has to be called with the lock held
+func (b *circuitBreaker) transition(ctx context.Context, to CircuitState) {
+	from := b.state
+	b.state = to
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+	if to == CircuitOpen {
+		b.openedAt = time.Now()
+	}
+
+	circuitBreakerState.WithLabelValues(b.name).Set(circuitStateValues[to])
+	circuitBreakerTransitions.WithLabelValues(b.name, string(from), string(to)).Inc()
+	logger.FromContext(ctx).Warn("circuit breaker state changed",
+		zap.String("circuit_breaker", b.name),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+	)
+}
+
+// allow reports whether the actions may be executed and returns the state they are executed in
+func (b *circuitBreaker) allow(ctx context.Context, config *CircuitBreakerConfig) (CircuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= config.OpenDuration.Duration {
+		b.transition(ctx, CircuitHalfOpen)
+	}
+
+	switch b.state {
+	case CircuitOpen:
+		return b.state, false
+	case CircuitHalfOpen:
+		if b.probes >= config.HalfOpenProbes {
+			return b.state, false
+		}
+		b.probes++
+	}
+
+	return b.state, true
+}
+
+// record updates the state with the outcome of actions executed in the given state
+func (b *circuitBreaker) record(ctx context.Context, config *CircuitBreakerConfig, state CircuitState, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The outcome is outdated if the state changed while the actions were executed
+	if state != b.state {
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		if err == nil {
+			b.failures = 0
+			return
+		}
+
+		b.failures++
+		if b.failures >= config.FailureThreshold {
+			b.transition(ctx, CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if err != nil {
+			b.transition(ctx, CircuitOpen)
+			return
+		}
+
+		b.successes++
+		if b.successes >= config.HalfOpenProbes {
+			b.transition(ctx, CircuitClosed)
+		}
+	}
+}
+
+func (a *CircuitBreaker) Execute(ctx context.Context, cfg map[string]any) (err error) {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	breaker := getCircuitBreaker(config.Name)
+	state, allowed := breaker.allow(ctx, config)
+
+	// The span tells a request rejected by the breaker apart from a failure of the dependency
+	ctx, span := tracing.StartSpan(ctx, "CircuitBreaker "+config.Name,
+		tracing.WithOperation("circuit_breaker"),
+		tracing.WithAttribute("circuit_breaker.name", config.Name),
+		tracing.WithAttribute("circuit_breaker.state", string(state)),
+		tracing.WithAttribute("circuit_breaker.rejected", !allowed),
+	)
+	defer func() {
+		span.End(err)
+	}()
+
+	if !allowed {
+		circuitBreakerRejections.WithLabelValues(config.Name).Inc()
+		return &CircuitOpenError{Name: config.Name, State: state}
+	}
+
+	// A panic counts as a failure, otherwise a half-open breaker would wait for the probe forever
+	defer func() {
+		if r := recover(); r != nil {
+			breaker.record(ctx, config, state, fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Execute(ctx)
+	breaker.record(ctx, config, state, err)
+
+	return err
+}
+
+func (a *CircuitBreaker) parseConfig(data map[string]any) (*CircuitBreakerConfig, error) {
+	config, err := pkg.ParseConfig[CircuitBreakerConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration.Duration == 0 {
+		config.OpenDuration.Duration = 10 * time.Second
+	}
+	if config.HalfOpenProbes == 0 {
+		config.HalfOpenProbes = 1
+	}
+
+	if config.FailureThreshold < 0 || config.HalfOpenProbes < 0 || config.OpenDuration.Duration < 0 {
+		return nil, fmt.Errorf("failure_threshold, open_duration and half_open_probes must not be negative")
+	}
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (a *CircuitBreaker) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["CircuitBreaker"] = &CircuitBreaker{}
+}
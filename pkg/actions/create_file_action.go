@@ -33,7 +33,11 @@ func (a *CreateFile) Execute(ctx context.Context, cfg map[string]any) error {
 		return err
 	}
 
-	defer f.Close()
+	openFiles.Inc()
+	defer func() {
+		f.Close()
+		openFiles.Dec()
+	}()
 
 	_, err = f.Write(make([]byte, config.Size))
 	if err != nil {
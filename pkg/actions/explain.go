@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// ExplainHeader requests the execution tree of the workload as the response body
+const ExplainHeader = "X-Chaosmania-Explain"
+
+const explainKey ContextKey = "explain"
+
+// ExplainTree is the execution tree of a workload on one server
+type ExplainTree struct {
+	Host     string         `json:"host"`
+	Duration string         `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Actions  []*ExplainNode `json:"actions"`
+
+	start time.Time
+	node  *ExplainNode
+}
+
+// ExplainNode is the execution of one action. Actions running other actions list them
+// as children, HTTPRequest actions carry the tree of the downstream server.
+type ExplainNode struct {
+	Name        string         `json:"name"`
+	StartOffset string         `json:"start_offset"`
+	Duration    string         `json:"duration"`
+	Error       string         `json:"error,omitempty"`
+	Actions     []*ExplainNode `json:"actions,omitempty"`
+	Downstream  *ExplainTree   `json:"downstream,omitempty"`
+
+	mu   sync.Mutex
+	tree *ExplainTree
+}
+
+// MarshalJSON encodes a snapshot of the node, its actions may still be running, like
+// actions abandoned by a Timeout
+func (n *ExplainNode) MarshalJSON() ([]byte, error) {
+	n.mu.Lock()
+	snapshot := struct {
+		Name        string         `json:"name"`
+		StartOffset string         `json:"start_offset"`
+		Duration    string         `json:"duration"`
+		Error       string         `json:"error,omitempty"`
+		Actions     []*ExplainNode `json:"actions,omitempty"`
+		Downstream  *ExplainTree   `json:"downstream,omitempty"`
+	}{
+		Name:        n.Name,
+		StartOffset: n.StartOffset,
+		Duration:    n.Duration,
+		Error:       n.Error,
+		Actions:     append([]*ExplainNode(nil), n.Actions...),
+		Downstream:  n.Downstream,
+	}
+	n.mu.Unlock()
+
+	return json.Marshal(snapshot)
+}
+
+// NewExplainContext starts recording the execution tree of the workloads executed with ctx
+func NewExplainContext(ctx context.Context) (context.Context, *ExplainTree) {
+	host, _ := os.Hostname()
+
+	tree := &ExplainTree{
+		Host:  host,
+		start: time.Now(),
+	}
+	tree.node = &ExplainNode{tree: tree}
+
+	return context.WithValue(ctx, explainKey, tree.node), tree
+}
+
+// Finish completes the tree with the outcome of the workload
+func (t *ExplainTree) Finish(err error) {
+	t.Duration = time.Since(t.start).String()
+	if err != nil {
+		t.Error = err.Error()
+	}
+
+	t.node.mu.Lock()
+	t.Actions = append([]*ExplainNode(nil), t.node.Actions...)
+	t.node.mu.Unlock()
+}
+
+func explainNodeFromContext(ctx context.Context) *ExplainNode {
+	node, _ := ctx.Value(explainKey).(*ExplainNode)
+	return node
+}
+
+// explainAction records the execution of an action below the current node, if the
+// execution tree is recorded. The returned context makes the action the current node,
+// done completes it.
+func explainAction(ctx context.Context, name string) (context.Context, func(error)) {
+	parent := explainNodeFromContext(ctx)
+	if parent == nil {
+		return ctx, func(error) {}
+	}
+
+	start := time.Now()
+	node := &ExplainNode{
+		Name:        name,
+		StartOffset: start.Sub(parent.tree.start).String(),
+		tree:        parent.tree,
+	}
+
+	parent.mu.Lock()
+	parent.Actions = append(parent.Actions, node)
+	parent.mu.Unlock()
+
+	return context.WithValue(ctx, explainKey, node), func(err error) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+
+		node.Duration = time.Since(start).String()
+		if err != nil {
+			node.Error = err.Error()
+		}
+	}
+}
+
+// setDownstream attaches the execution tree returned by a downstream server to the current action
+func setDownstream(ctx context.Context, tree *ExplainTree) {
+	node := explainNodeFromContext(ctx)
+	if node == nil {
+		return
+	}
+
+	node.mu.Lock()
+	node.Downstream = tree
+	node.mu.Unlock()
+}
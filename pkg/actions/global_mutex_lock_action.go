@@ -36,7 +36,10 @@ func (a *GlobalMutexLock) Execute(ctx context.Context, cfg map[string]any) error
 	lock = GLOBAL_MUTEX_LOCKS[config.Id]
 	GLOBAL_MUTEX.Unlock()
 
+	locksWaiting.Inc()
 	lock.Lock()
+	locksWaiting.Dec()
+	locksHeld.Inc()
 
 	return nil
 }
@@ -28,6 +28,7 @@ func (a *GlobalMutexUnlock) Execute(ctx context.Context, cfg map[string]any) err
 	_, ok := GLOBAL_MUTEX_LOCKS[config.Id]
 	if ok {
 		GLOBAL_MUTEX_LOCKS[config.Id].Unlock()
+		locksHeld.Dec()
 	}
 
 	return nil
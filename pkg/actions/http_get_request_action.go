@@ -9,9 +9,8 @@ import (
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"go.uber.org/zap"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 )
 
 type HTTPGetRequest struct{}
@@ -35,18 +34,10 @@ func (a *HTTPGetRequest) Execute(ctx context.Context, cfg map[string]any) error
 	}
 
 	// Send the request to the server
-	var resp *http.Response
-	if pkg.IsDatadogEnabled() {
-		client := httptrace.WrapClient(http.DefaultClient)
-		resp, err = client.Do(req)
-	} else if pkg.IsOpenTelemetryEnabled() {
-		client := &http.Client{
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-		}
-		resp, err = client.Do(req)
-	} else {
-		resp, err = http.DefaultClient.Do(req)
+	client := &http.Client{
+		Transport: tracing.Get().WrapTransport(http.DefaultTransport),
 	}
+	resp, err := client.Do(req)
 
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to send request", zap.Error(err))
@@ -57,12 +48,12 @@ func (a *HTTPGetRequest) Execute(ctx context.Context, cfg map[string]any) error
 	fmt.Println(resp.StatusCode)
 
 	if resp.StatusCode >= 400 {
-		body, err := io.ReadAll(req.Body)
+		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			logger.FromContext(ctx).Warn("failed to read body", zap.Error(err))
 			return err
 		}
-		return fmt.Errorf("request failed (%v): %s", resp.StatusCode, string(body))
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Message: string(body)}
 	}
 
 	return nil
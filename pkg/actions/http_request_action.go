@@ -10,13 +10,22 @@ import (
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"go.uber.org/zap"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 )
 
 type HTTPRequest struct{}
 
+// HTTPStatusError is returned by the HTTP actions for responses with an error status code
+type HTTPStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("request failed (%v): %s", e.StatusCode, e.Message)
+}
+
 type HTTPRequestConfig struct {
 	Url  string         `json:"url"`
 	Body map[string]any `json:"body"`
@@ -45,19 +54,19 @@ func (a *HTTPRequest) Execute(ctx context.Context, cfg map[string]any) error {
 	// Set the content type header to indicate a JSON payload
 	req.Header.Set("Content-Type", "application/json")
 
+	setDownstreamSeed(ctx, req.Header)
+
+	// Ask the downstream server for its execution tree if ours is recorded
+	explain := explainNodeFromContext(ctx) != nil
+	if explain {
+		req.Header.Set(ExplainHeader, "true")
+	}
+
 	// Send the request to the server
-	var resp *http.Response
-	if pkg.IsDatadogEnabled() {
-		client := httptrace.WrapClient(http.DefaultClient)
-		resp, err = client.Do(req)
-	} else if pkg.IsOpenTelemetryEnabled() {
-		client := &http.Client{
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-		}
-		resp, err = client.Do(req)
-	} else {
-		resp, err = http.DefaultClient.Do(req)
+	client := &http.Client{
+		Transport: tracing.Get().WrapTransport(http.DefaultTransport),
 	}
+	resp, err := client.Do(req)
 
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to send request", zap.Error(err))
@@ -65,13 +74,28 @@ func (a *HTTPRequest) Execute(ctx context.Context, cfg map[string]any) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		body, err := io.ReadAll(req.Body)
-		if err != nil {
-			logger.FromContext(ctx).Warn("failed to read body", zap.Error(err))
-			return err
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to read body", zap.Error(err))
+		return err
+	}
+
+	message := string(body)
+	if explain && resp.Header.Get(ExplainHeader) != "" {
+		var tree ExplainTree
+		if err := json.Unmarshal(body, &tree); err == nil {
+			setDownstream(ctx, &tree)
+			message = tree.Error
+			if message == "" {
+				message = http.StatusText(resp.StatusCode)
+			}
+		} else {
+			logger.FromContext(ctx).Warn("failed to parse downstream execution tree", zap.Error(err))
 		}
-		return fmt.Errorf("request failed (%v): %s", resp.StatusCode, string(body))
+	}
+
+	if resp.StatusCode >= 400 {
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Message: message}
 	}
 
 	return nil
@@ -28,7 +28,9 @@ func (a *HTTPResponse) Execute(ctx context.Context, cfg map[string]any) error {
 
 	val := ctx.Value(ResponseWriterKey)
 	if val == nil {
-		panic("http.ResponseWriter not found in context")
+		// Async jobs have already responded with their job ID
+		logger.FromContext(ctx).Warn("no http response to write, ignoring status code", zap.Int("statusCode", config.StatusCode))
+		return nil
 	}
 
 	w := val.(http.ResponseWriter)
@@ -4,26 +4,24 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
-	"sync"
+	"fmt"
 	"time"
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/IBM/sarama"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
-	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	saramatrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/IBM/sarama.v1"
 	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
 	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 type KafkaConsumerService struct {
+	ServiceStats
+
 	name   BackgroundServiceName
 	config *KafkaConsumerServiceConfig
 	cfg    *sarama.Config
@@ -58,31 +56,22 @@ func (s *KafkaConsumerService) Run(ctx context.Context) error {
 
 	defer client.Close()
 
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			// `Consume` should be called inside an infinite loop, when a
-			// server-side rebalance happens, the consumer session will need to be
-			// recreated to get the new claims
-			if err := client.Consume(ctx, []string{s.config.Topic}, s); err != nil {
-				if errors.Is(err, sarama.ErrClosedConsumerGroup) {
-					return
-				}
-
-				logger.FromContext(ctx).Warn("failed to consume message", zap.Error(err))
-			}
-			// check if context was cancelled, signaling that the consumer should stop
-			if ctx.Err() != nil {
-				return
-			}
-		}
-	}()
+	for {
+		// `Consume` should be called inside an infinite loop, when a
+		// server-side rebalance happens, the consumer session will need to be
+		// recreated to get the new claims
+		err := client.Consume(ctx, []string{s.config.Topic}, s)
 
-	wg.Wait()
+		// check if context was cancelled, signaling that the consumer should stop
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) || ctx.Err() != nil {
+			return nil
+		}
 
-	return nil
+		// The supervisor restarts the consumer with a backoff
+		if err != nil {
+			return fmt.Errorf("failed to consume messages: %w", err)
+		}
+	}
 }
 
 func NewKafkaConsumerService(name BackgroundServiceName, config map[string]any) (BackgroundService, error) {
@@ -151,8 +140,12 @@ func (consumer *KafkaConsumerService) ConsumeClaim(session sarama.ConsumerGroupS
 			if !ok {
 				return nil
 			}
-			err := consumer.handleMessage(session.Context(), message)
+			// Finish handling the message when stopping
+			err := consumer.handleMessage(context.WithoutCancel(session.Context()), message)
+			consumer.Inc("messages_consumed")
 			if err != nil {
+				consumer.Inc("message_errors")
+				consumer.SetError(err)
 				logger.FromContext(session.Context()).Warn("failed to handle message", zap.Error(err))
 			}
 
@@ -173,30 +166,27 @@ func getConsumerMsgSize(msg *sarama.ConsumerMessage) (size int64) {
 	return size + int64(len(msg.Value)+len(msg.Key))
 }
 
-func (consumer *KafkaConsumerService) ddHandleMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "process_message",
-		tracer.ResourceName("process_message"),
+func (consumer *KafkaConsumerService) handleMessage(ctx context.Context, message *sarama.ConsumerMessage) (err error) {
+	// Continue the trace of the producer
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	ctx = tracing.Get().Extract(ctx, headers)
+
+	ctx, span := tracing.StartSpan(ctx, "Consume Topic "+consumer.config.Topic,
+		tracing.WithKind(tracing.KindConsumer),
+		tracing.WithOperation("kafka.consume"),
+		tracing.WithPeer(consumer.config.PeerService, consumer.config.PeerNamespace),
+		tracing.WithAttribute(string(semconv.MessagingSystemKey), "kafka"),
+		tracing.WithAttribute(string(semconv.MessagingDestinationNameKey), consumer.config.Topic),
+		tracing.WithAttribute(string(semconv.MessagingKafkaConsumerGroupKey), consumer.config.Group),
+		tracing.WithAttribute(string(semconv.MessagingKafkaDestinationPartitionKey), int(message.Partition)),
+		tracing.WithAttribute(string(semconv.MessagingKafkaMessageOffsetKey), int(message.Offset)),
 	)
-	defer span.Finish()
-
-	// This span is just to show the relationship between the kafka consumer and the topic.
-	child := tracer.StartSpan("kafka.consume",
-		tracer.ResourceName("Consume Topic "+consumer.config.Topic),
-		tracer.SpanType(ext.SpanTypeMessageConsumer),
-
-		tracer.Tag(ext.MessagingKafkaPartition, message.Partition),
-		tracer.Tag("offset", message.Offset),
-		tracer.Tag(ext.Component, "IBM/sarama"),
-		tracer.Tag(ext.SpanKind, ext.SpanKindConsumer), // Required tag
-		tracer.Tag(ext.MessagingSystem, ext.MessagingSystemKafka),
-		tracer.Measured(),
-
-		tracer.Tag("topic", consumer.config.Topic),      // Required tag
-		tracer.Tag("group", consumer.config.Group),      // Required tag
-		tracer.ServiceName(consumer.config.PeerService), // Required tag
-		tracer.ChildOf(span.Context()),
-	)
-	child.Finish()
+	defer func() {
+		span.End(err)
+	}()
 
 	setConsumeCheckpoint(true, consumer.config.Group, message)
 
@@ -210,48 +200,7 @@ func (consumer *KafkaConsumerService) ddHandleMessage(ctx context.Context, messa
 		return err
 	}
 
-	err = ACTIONS["Script"].Execute(ctx, c)
-	span.Finish(tracer.WithError(err))
-	return err
-}
-
-func (consumer *KafkaConsumerService) handleMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
-	if pkg.IsDatadogEnabled() {
-		return consumer.ddHandleMessage(ctx, message)
-	}
-	consumeTracer := otel.GetTracerProvider().Tracer("kafka-consumer")
-	ctx, span := consumeTracer.Start(ctx, "Consume Topic "+consumer.config.Topic, oteltrace.WithSpanKind(oteltrace.SpanKindConsumer))
-	defer span.End()
-	span.SetAttributes(semconv.MessagingKafkaDestinationPartition(int(message.Partition)))
-	span.SetAttributes(semconv.MessagingKafkaMessageOffset(int(message.Offset)))
-	span.SetAttributes(semconv.MessagingDestinationName(consumer.config.Topic))
-	span.SetAttributes(semconv.MessagingKafkaConsumerGroup(consumer.config.Group))
-	span.SetAttributes(semconv.MessagingSystemKafka)
-
-	// https://opentelemetry.io/docs/specs/semconv/messaging/kafka/#:~:text=For%20Apache%20Kafka%20producers
-	span.SetAttributes(semconv.PeerService(consumer.config.PeerService))
-	span.SetAttributes(attribute.String("peer.namespace", consumer.config.PeerNamespace))
-
-	//setConsumeCheckpoint(true, consumer.config.Group, message)
-
-	cfg := ScriptConfig{
-		Script:  consumer.config.Script,
-		Message: string(message.Value),
-	}
-
-	c, err := pkg.ConfigToMap(&cfg)
-	if err != nil {
-		return err
-	}
-
-	err = ACTIONS["Script"].Execute(ctx, c)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-	} else {
-		span.SetStatus(codes.Ok, "message received")
-	}
-	return err
+	return ACTIONS["Script"].Execute(ctx, c)
 }
 
 func setConsumeCheckpoint(enabled bool, groupID string, msg *sarama.ConsumerMessage) {
@@ -7,12 +7,9 @@ import (
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/IBM/sarama"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
-	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	saramatrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/IBM/sarama.v1"
 	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
@@ -79,16 +76,21 @@ func getProducerMsgSize(msg *sarama.ProducerMessage) (size int64) {
 	return size
 }
 
-func (producer *KafkaProducerService) ddProduce(ctx context.Context, topic string, msg string) error {
-	span, _ := tracer.SpanFromContext(ctx)
+func (producer *KafkaProducerService) Produce(ctx context.Context, topic string, msg string) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "Produce Message",
+		tracing.WithKind(tracing.KindProducer),
+		tracing.WithOperation("kafka.produce"),
+		tracing.WithPeer(producer.config.PeerService, producer.config.PeerNamespace),
+		tracing.WithAttribute(string(semconv.MessagingSystemKey), "kafka"),
+		tracing.WithAttribute(string(semconv.MessagingDestinationNameKey), topic),
+	)
+	defer func() {
+		span.End(err)
+	}()
 
 	m := &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(msg)}
-
-	carrier := saramatrace.NewProducerMessageCarrier(m)
-	err := tracer.Inject(span.Context(), carrier)
-	if err != nil {
-		logger.FromContext(ctx).Warn("failed to inject span", zap.Error(err))
-		return err
+	for k, v := range tracing.Get().Inject(ctx) {
+		m.Headers = append(m.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
 	}
 
 	setProduceCheckpoint(m)
@@ -96,51 +98,16 @@ func (producer *KafkaProducerService) ddProduce(ctx context.Context, topic strin
 	partition, offset, err := producer.producer.SendMessage(m)
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to send message", zap.Error(err))
-		//span.Finish(tracer.WithError(err))
 		return err
 	}
 
+	span.SetAttribute(string(semconv.MessagingKafkaDestinationPartitionKey), int(partition))
+	span.SetAttribute(string(semconv.MessagingKafkaMessageOffsetKey), int(offset))
 	tracer.TrackKafkaProduceOffset(topic, partition, offset)
 
 	return nil
 }
 
-func (producer *KafkaProducerService) Produce(ctx context.Context, topic string, msg string) error {
-	if pkg.IsDatadogEnabled() {
-		return producer.ddProduce(ctx, topic, msg)
-	}
-
-	prodTracer := otel.GetTracerProvider().Tracer("kafka-producer")
-
-	ctx, span := prodTracer.Start(ctx, "Produce Message", oteltrace.WithSpanKind(oteltrace.SpanKindProducer))
-	defer span.End()
-
-	m := &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(msg)}
-
-	span.SetAttributes(semconv.MessagingKafkaDestinationPartition(int(m.Partition)))
-	span.SetAttributes(semconv.MessagingKafkaMessageOffset(int(m.Offset)))
-	span.SetAttributes(semconv.MessagingDestinationName(topic))
-	span.SetAttributes(semconv.MessagingSystemKafka)
-
-	// https://opentelemetry.io/docs/specs/semconv/messaging/kafka/#:~:text=For%20Apache%20Kafka%20producers
-	span.SetAttributes(semconv.PeerService(producer.config.PeerService))
-	span.SetAttributes(attribute.String("peer.namespace", producer.config.PeerNamespace))
-
-	setProduceCheckpoint(m)
-
-	_, _, err := producer.producer.SendMessage(m)
-	if err != nil {
-		logger.FromContext(ctx).Warn("failed to send message", zap.Error(err))
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
-	} else {
-		span.SetStatus(codes.Ok, "message sent")
-	}
-
-	return nil
-}
-
 func NewKafkaProducerService(name ServiceName, config map[string]any) (Service, error) {
 	cfg, err := pkg.ParseConfig[KafkaProducerServiceConfig](config)
 	if err != nil {
@@ -176,10 +143,6 @@ func NewKafkaProducerService(name ServiceName, config map[string]any) (Service,
 		return nil, err
 	}
 
-	producer = saramatrace.WrapSyncProducer(c,
-		producer,
-		saramatrace.WithServiceName(cfg.PeerService))
-
 	kafkaService.producer = producer
 
 	return &kafkaService, nil
@@ -0,0 +1,210 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DescriptorFile = "file"
+	DescriptorPipe = "pipe"
+	DescriptorTCP  = "tcp"
+)
+
+// fdLeak holds the descriptors leaked by LeakFileDescriptors until ReleaseFileDescriptors
+// closes them
+type fdLeak struct {
+	mu      sync.Mutex
+	count   int
+	closers []io.Closer
+	// files are removed on release
+	files []string
+}
+
+var LEAKED_FILE_DESCRIPTORS = &fdLeak{}
+
+// LeakFileDescriptors opens files, pipes or TCP connections and never closes them, until the
+// process runs out of descriptors ("too many open files") or ephemeral ports. Pipes use two
+// descriptors.
+type LeakFileDescriptors struct{}
+
+type LeakFileDescriptorsConfig struct {
+	Count int    `json:"count"`
+	Kind  string `json:"kind"`
+	// Directory is where files are created, the temporary directory by default
+	Directory string `json:"directory"`
+	// Address is the host:port TCP connections are opened to
+	Address string `json:"address"`
+	// MaxDescriptors stops leaking once this many leaked descriptors are open
+	MaxDescriptors int `json:"max_descriptors"`
+}
+
+func (a *LeakFileDescriptors) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	for i := 0; i < config.Count && LEAKED_FILE_DESCRIPTORS.fits(config); i++ {
+		// Opening, like dialing a slow address, happens without the lock so the leak can
+		// be released meanwhile
+		closers, filename, err := openDescriptors(ctx, config)
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to open descriptor", zap.String("kind", config.Kind), zap.Error(err))
+			return err
+		}
+
+		if !LEAKED_FILE_DESCRIPTORS.add(config, closers, filename) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// openDescriptors opens the descriptors of the configured kind, two for a pipe. It returns
+// the name of the file opened, if any.
+func openDescriptors(ctx context.Context, config *LeakFileDescriptorsConfig) ([]io.Closer, string, error) {
+	switch config.Kind {
+	case DescriptorFile:
+		filename := filepath.Join(config.Directory, "chaosmania-"+uuid.NewString())
+		f, err := os.Create(filename)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return []io.Closer{f}, filename, nil
+	case DescriptorPipe:
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return []io.Closer{r, w}, "", nil
+	default:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", config.Address)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return []io.Closer{conn}, "", nil
+	}
+}
+
+// fits reports whether the descriptors of one more leak stay within the cap
+func (l *fdLeak) fits(config *LeakFileDescriptorsConfig) bool {
+	descriptors := 1
+	if config.Kind == DescriptorPipe {
+		descriptors = 2
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.count+descriptors <= config.MaxDescriptors
+}
+
+// add leaks the descriptors unless concurrent calls reached the cap meanwhile, then it
+// closes them and returns false
+func (l *fdLeak) add(config *LeakFileDescriptorsConfig, closers []io.Closer, filename string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count+len(closers) > config.MaxDescriptors {
+		for _, closer := range closers {
+			closer.Close()
+		}
+		if filename != "" {
+			os.Remove(filename)
+		}
+
+		return false
+	}
+
+	l.count += len(closers)
+	l.closers = append(l.closers, closers...)
+	if filename != "" {
+		l.files = append(l.files, filename)
+	}
+	leakedFileDescriptors.WithLabelValues(config.Kind).Add(float64(len(closers)))
+
+	return true
+}
+
+// Release closes all leaked descriptors, removes the leaked files and returns how many
+// descriptors were open
+func (l *fdLeak) Release() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, closer := range l.closers {
+		closer.Close()
+	}
+	for _, filename := range l.files {
+		os.Remove(filename)
+	}
+
+	released := l.count
+	l.count = 0
+	l.closers = nil
+	l.files = nil
+	leakedFileDescriptors.Reset()
+
+	return released
+}
+
+func (a *LeakFileDescriptors) parseConfig(data map[string]any) (*LeakFileDescriptorsConfig, error) {
+	config, err := pkg.ParseConfig[LeakFileDescriptorsConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Count == 0 {
+		config.Count = 1
+	}
+	if config.Kind == "" {
+		config.Kind = DescriptorFile
+	}
+	if config.Directory == "" {
+		config.Directory = os.TempDir()
+	}
+	if config.MaxDescriptors == 0 {
+		config.MaxDescriptors = 1000
+	}
+
+	switch config.Kind {
+	case DescriptorFile, DescriptorPipe:
+	case DescriptorTCP:
+		if config.Address == "" {
+			return nil, fmt.Errorf("address is required for kind %s", DescriptorTCP)
+		}
+	default:
+		return nil, fmt.Errorf("invalid kind: %s. Must be one of: %s, %s, %s", config.Kind, DescriptorFile, DescriptorPipe, DescriptorTCP)
+	}
+
+	if config.Count < 0 || config.MaxDescriptors < 0 {
+		return nil, fmt.Errorf("count and max_descriptors must not be negative")
+	}
+
+	return config, nil
+}
+
+func (a *LeakFileDescriptors) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["LeakFileDescriptors"] = &LeakFileDescriptors{}
+}
@@ -0,0 +1,205 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	BlockChannel = "channel"
+	BlockTimer   = "timer"
+	BlockNetwork = "network"
+)
+
+// goroutineLeak holds the goroutines leaked by LeakGoroutines until ReleaseGoroutines
+// closes the release channel, the connections they read from and their listener
+type goroutineLeak struct {
+	mu       sync.Mutex
+	count    int
+	release  chan struct{}
+	listener net.Listener
+	conns    []net.Conn
+}
+
+var LEAKED_GOROUTINES = &goroutineLeak{release: make(chan struct{})}
+
+// LeakGoroutines starts goroutines that never return, like handlers waiting for a reply
+// nobody sends. They are blocked on a channel, a timer or a read from a loopback TCP
+// connection that never receives data, and carry the pprof label leak=<block>.
+type LeakGoroutines struct{}
+
+type LeakGoroutinesConfig struct {
+	Count int    `json:"count"`
+	Block string `json:"block"`
+	// Duration is the time timer goroutines wait before they return
+	Duration pkg.Duration `json:"duration"`
+	// MaxGoroutines stops leaking once this many leaked goroutines are running
+	MaxGoroutines int `json:"max_goroutines"`
+}
+
+func (a *LeakGoroutines) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	l := LEAKED_GOROUTINES
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer func() {
+		leakedGoroutines.Set(float64(l.count))
+	}()
+
+	for i := 0; i < config.Count && l.count < config.MaxGoroutines; i++ {
+		var conn net.Conn
+		if config.Block == BlockNetwork {
+			conn, err = l.dial()
+			if err != nil {
+				logger.FromContext(ctx).Warn("failed to open connection", zap.Error(err))
+				return err
+			}
+		}
+
+		l.count++
+		go l.block(config, conn, l.release)
+	}
+
+	return nil
+}
+
+func (l *goroutineLeak) block(config *LeakGoroutinesConfig, conn net.Conn, release chan struct{}) {
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("leak", config.Block)))
+
+	defer func() {
+		l.mu.Lock()
+		l.count--
+		leakedGoroutines.Set(float64(l.count))
+		l.mu.Unlock()
+	}()
+
+	switch config.Block {
+	case BlockChannel:
+		<-release
+	case BlockTimer:
+		timer := time.NewTimer(config.Duration.Duration)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-release:
+		}
+	case BlockNetwork:
+		// Returns once ReleaseGoroutines closes the connection
+		conn.Read(make([]byte, 1))
+	}
+}
+
+// dial connects to a loopback listener accepting connections it never writes to.
+// Callers must hold the lock.
+func (l *goroutineLeak) dial() (net.Conn, error) {
+	if l.listener == nil {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+
+		l.listener = listener
+		go l.accept(listener)
+	}
+
+	conn, err := net.Dial("tcp", l.listener.Addr().String())
+	if err != nil {
+		return nil, err
+	}
+
+	l.conns = append(l.conns, conn)
+	return conn, nil
+}
+
+func (l *goroutineLeak) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		l.mu.Lock()
+		if l.listener != listener {
+			// Accepted while Release closed the listener
+			conn.Close()
+		} else {
+			l.conns = append(l.conns, conn)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Release unblocks all leaked goroutines and returns how many were running
+func (l *goroutineLeak) Release() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	close(l.release)
+	l.release = make(chan struct{})
+
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+
+	if l.listener != nil {
+		l.listener.Close()
+		l.listener = nil
+	}
+
+	return l.count
+}
+
+func (a *LeakGoroutines) parseConfig(data map[string]any) (*LeakGoroutinesConfig, error) {
+	config, err := pkg.ParseConfig[LeakGoroutinesConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Count == 0 {
+		config.Count = 1
+	}
+	if config.Block == "" {
+		config.Block = BlockChannel
+	}
+	if config.Duration.Duration == 0 {
+		config.Duration.Duration = 24 * time.Hour
+	}
+	if config.MaxGoroutines == 0 {
+		config.MaxGoroutines = 10000
+	}
+
+	switch config.Block {
+	case BlockChannel, BlockTimer, BlockNetwork:
+	default:
+		return nil, fmt.Errorf("invalid block: %s. Must be one of: %s, %s, %s", config.Block, BlockChannel, BlockTimer, BlockNetwork)
+	}
+
+	if config.Count < 0 || config.MaxGoroutines < 0 || config.Duration.Duration < 0 {
+		return nil, fmt.Errorf("count, max_goroutines and duration must not be negative")
+	}
+
+	return config, nil
+}
+
+func (a *LeakGoroutines) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["LeakGoroutines"] = &LeakGoroutines{}
+}
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Loop repeats its actions a number of times, for a duration or until they fail.
+// With several limits the loop ends at the first one reached.
+type Loop struct{}
+
+type LoopConfig struct {
+	Actions  []ActionConfig `json:"actions"`
+	Times    int            `json:"times"`
+	Duration pkg.Duration   `json:"duration"`
+	// UntilError repeats the actions without limit until they fail
+	UntilError bool `json:"until_error"`
+	// IgnoreErrors continues with the next iteration when the actions fail
+	IgnoreErrors bool `json:"ignore_errors"`
+	// Delay is waited between iterations
+	Delay pkg.Duration `json:"delay"`
+}
+
+func (a *Loop) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	workload := Workload{Actions: config.Actions}
+
+	var deadline <-chan time.Time
+	if config.Duration.Duration > 0 {
+		timer := time.NewTimer(config.Duration.Duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for i := 0; config.Times == 0 || i < config.Times; i++ {
+		if i > 0 && config.Delay.Duration > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-deadline:
+				return nil
+			case <-time.After(config.Delay.Duration):
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return nil
+		default:
+		}
+
+		err := workload.Execute(ctx)
+		if err != nil {
+			if !config.IgnoreErrors || ctx.Err() != nil {
+				return fmt.Errorf("iteration %d: %w", i+1, err)
+			}
+
+			logger.FromContext(ctx).Warn("loop iteration failed, continuing", zap.Error(err), zap.Int("iteration", i+1))
+		}
+	}
+
+	return nil
+}
+
+func (a *Loop) parseConfig(data map[string]any) (*LoopConfig, error) {
+	config, err := pkg.ParseConfig[LoopConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Times < 0 || config.Duration.Duration < 0 || config.Delay.Duration < 0 {
+		return nil, fmt.Errorf("times, duration and delay must not be negative")
+	}
+
+	if config.Times == 0 && config.Duration.Duration == 0 && !config.UntilError {
+		return nil, fmt.Errorf("one of times, duration or until_error is required")
+	}
+
+	if config.UntilError && config.IgnoreErrors {
+		return nil, fmt.Errorf("until_error and ignore_errors are exclusive")
+	}
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (a *Loop) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["Loop"] = &Loop{}
+}
@@ -0,0 +1,159 @@
+package actions
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var actionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "chaosmania_action_duration_seconds",
+	Help: "The execution duration of actions",
+}, []string{"action", "peer_service"})
+
+var actionErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_action_errors_total",
+	Help: "The number of failed action executions",
+}, []string{"action", "peer_service"})
+
+var locksHeld = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_locks_held",
+	Help: "The number of global mutex locks currently held",
+})
+
+var locksWaiting = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_locks_waiting",
+	Help: "The number of actions waiting for a global mutex lock",
+})
+
+var leakedMemoryBytes = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_leaked_memory_bytes",
+	Help: "The number of bytes leaked by AllocateMemory",
+})
+
+var leakedGoroutines = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_leaked_goroutines",
+	Help: "The number of goroutines leaked by LeakGoroutines",
+})
+
+var openFiles = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_open_files",
+	Help: "The number of files currently opened by actions",
+})
+
+var leakedFileDescriptors = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chaosmania_leaked_file_descriptors",
+	Help: "The number of file descriptors leaked by LeakFileDescriptors",
+}, []string{"kind"})
+
+var backgroundServiceRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_background_service_restarts_total",
+	Help: "The number of times background services were restarted",
+}, []string{"service", "type"})
+
+var backgroundServicesUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chaosmania_background_service_up",
+	Help: "Whether a background service is currently running",
+}, []string{"service", "type"})
+
+var circuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "chaosmania_circuit_breaker_state",
+	Help: "The state of circuit breakers: 0 closed, 1 open, 2 half-open",
+}, []string{"name"})
+
+var circuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_circuit_breaker_transitions_total",
+	Help: "The number of state changes of circuit breakers",
+}, []string{"name", "from", "to"})
+
+var circuitBreakerRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaosmania_circuit_breaker_rejected_total",
+	Help: "The number of executions rejected by open circuit breakers",
+}, []string{"name"})
+
+var spawnedTasksPending = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "chaosmania_spawned_tasks_pending",
+	Help: "The number of tasks started by Spawn that have not completed",
+})
+
+var spawnedTasksRejected = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "chaosmania_spawned_tasks_rejected_total",
+	Help: "The number of tasks rejected by Spawn because too many were pending",
+})
+
+// peerService returns the service a dependency action talks to: the peer_service of
+// its config or connection, otherwise the host of its url or address. It returns ""
+// for actions that have no dependency in their config, like scripts using services
+// by name, see withPeerRecorder.
+func peerService(config map[string]any) string {
+	if peer, ok := config["peer_service"].(string); ok && peer != "" {
+		return peer
+	}
+
+	if connection, ok := config["connection"].(map[string]any); ok {
+		if peer, ok := connection["peer_service"].(string); ok && peer != "" {
+			return peer
+		}
+	}
+
+	if rawUrl, ok := config["url"].(string); ok {
+		if u, err := url.Parse(rawUrl); err == nil {
+			return u.Hostname()
+		}
+	}
+
+	if address, ok := config["address"].(string); ok {
+		if u, err := url.Parse(address); err == nil && u.Host != "" {
+			return u.Hostname()
+		}
+
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			return host
+		}
+
+		return address
+	}
+
+	return ""
+}
+
+const peerKey ContextKey = "peer"
+
+// peerRecorder holds the first service an action looked up by name while executing
+type peerRecorder struct {
+	mu   sync.Mutex
+	peer string
+}
+
+// withPeerRecorder returns a context recording the services looked up by name with it
+func withPeerRecorder(ctx context.Context) (context.Context, *peerRecorder) {
+	recorder := &peerRecorder{}
+	return context.WithValue(ctx, peerKey, recorder), recorder
+}
+
+// recordPeer notes the peer service of a service looked up by name for the action
+// executing with ctx
+func recordPeer(ctx context.Context, name ServiceName) {
+	recorder, ok := ctx.Value(peerKey).(*peerRecorder)
+	if !ok {
+		return
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.peer == "" {
+		recorder.peer = Manager.PeerService(name)
+	}
+}
+
+func (r *peerRecorder) get() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.peer
+}
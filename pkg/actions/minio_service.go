@@ -3,20 +3,15 @@ package actions
 import (
 	"context"
 	"github.com/Causely/chaosmania/pkg/logger"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
-	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"io"
 	"strings"
 
 	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 type MinioBucket struct {
@@ -47,116 +42,62 @@ func (ms *MinioService) Type() ServiceType {
 	return "minio"
 }
 
-func (ms *MinioService) ddStartSpan(ctx context.Context, resource string) tracer.Span {
-	span, _ := tracer.SpanFromContext(ctx)
-
-	return tracer.StartSpan("minio.command",
-		tracer.ResourceName(resource),
-		tracer.ServiceName(ms.config.PeerService),
-		tracer.ChildOf(span.Context()),
-		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
-		tracer.Tag("out.host", ms.config.Endpoint),
+func (ms *MinioService) startSpan(ctx context.Context, operation string, bucket string) (context.Context, tracing.Span) {
+	return tracing.StartSpan(ctx, operation,
+		tracing.WithKind(tracing.KindClient),
+		tracing.WithOperation("minio.command"),
+		tracing.WithPeer(ms.config.PeerService, ms.config.PeerNamespace),
+		tracing.WithAttribute(string(semconv.AWSS3BucketKey), bucket),
+		tracing.WithAttribute("out.host", ms.config.Endpoint),
 	)
 }
 
-func (ms *MinioService) Get_object(ctx context.Context, bucket string, object string) (string, error) {
-	var child tracer.Span
-	var span oteltrace.Span
-	if pkg.IsDatadogEnabled() {
-		child = ms.ddStartSpan(ctx, "GetObject")
-		defer child.Finish()
-	} else {
-		prodTracer := otel.GetTracerProvider().Tracer("minio-storage")
-
-		_, span = prodTracer.Start(ctx, "GetObject", oteltrace.WithSpanKind(oteltrace.SpanKindClient))
-		defer span.End()
-
-		span.SetAttributes(semconv.AWSS3Bucket(bucket))
-		span.SetAttributes(semconv.PeerService(ms.config.PeerService))
-		span.SetAttributes(attribute.String("peer.namespace", ms.config.PeerNamespace))
-	}
+func (ms *MinioService) Get_object(ctx context.Context, bucket string, object string) (data string, err error) {
+	ctx, span := ms.startSpan(ctx, "GetObject", bucket)
+	defer func() {
+		span.End(err)
+	}()
 
 	obj, err := ms.client.GetObject(ctx, bucket, object, minio.GetObjectOptions{})
 	if err != nil {
-		child.Finish(tracer.WithError(err))
 		return "", err
 	}
 
-	data, err := io.ReadAll(obj)
+	bytes, err := io.ReadAll(obj)
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to send message", zap.Error(err))
-		if pkg.IsDatadogEnabled() {
-			child.Finish(tracer.WithError(err))
-		} else {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
 		return "", err
 	}
 
-	return string(data), nil
+	return string(bytes), nil
 }
 
-func (ms *MinioService) Put_object(ctx context.Context, bucket string, object string, data string) error {
-	var child tracer.Span
-	var span oteltrace.Span
-	if pkg.IsDatadogEnabled() {
-		child := ms.ddStartSpan(ctx, "PutObject")
-		defer child.Finish()
-	} else {
-		prodTracer := otel.GetTracerProvider().Tracer("minio-storage")
-
-		_, span = prodTracer.Start(ctx, "PutObject", oteltrace.WithSpanKind(oteltrace.SpanKindClient))
-		defer span.End()
-
-		span.SetAttributes(semconv.AWSS3Bucket(bucket))
-		span.SetAttributes(semconv.PeerService(ms.config.PeerService))
-		span.SetAttributes(attribute.String("peer.namespace", ms.config.PeerNamespace))
-	}
+func (ms *MinioService) Put_object(ctx context.Context, bucket string, object string, data string) (err error) {
+	ctx, span := ms.startSpan(ctx, "PutObject", bucket)
+	defer func() {
+		span.End(err)
+	}()
 
 	length := len(data)
 	reader := strings.NewReader(data)
-	_, err := ms.client.PutObject(ctx, bucket, object, reader, int64(length), minio.PutObjectOptions{ContentType: "text/plain"})
+	_, err = ms.client.PutObject(ctx, bucket, object, reader, int64(length), minio.PutObjectOptions{ContentType: "text/plain"})
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to send message", zap.Error(err))
-		if pkg.IsDatadogEnabled() {
-			child.Finish(tracer.WithError(err))
-		} else {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
 		return err
 	}
 
 	return nil
 }
 
-func (ms *MinioService) Remove_object(ctx context.Context, bucket string, object string) error {
-	var child tracer.Span
-	var span oteltrace.Span
-	if pkg.IsDatadogEnabled() {
-		child := ms.ddStartSpan(ctx, "RemoveObject")
-		defer child.Finish()
-	} else {
-		prodTracer := otel.GetTracerProvider().Tracer("minio-storage")
-
-		_, span = prodTracer.Start(ctx, "RemoveObject", oteltrace.WithSpanKind(oteltrace.SpanKindClient))
-		defer span.End()
-
-		span.SetAttributes(semconv.AWSS3Bucket(bucket))
-		span.SetAttributes(semconv.PeerService(ms.config.PeerService))
-		span.SetAttributes(attribute.String("peer.namespace", ms.config.PeerNamespace))
-	}
+func (ms *MinioService) Remove_object(ctx context.Context, bucket string, object string) (err error) {
+	ctx, span := ms.startSpan(ctx, "RemoveObject", bucket)
+	defer func() {
+		span.End(err)
+	}()
 
-	err := ms.client.RemoveObject(ctx, bucket, object, minio.RemoveObjectOptions{})
+	err = ms.client.RemoveObject(ctx, bucket, object, minio.RemoveObjectOptions{})
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to send message", zap.Error(err))
-		if pkg.IsDatadogEnabled() {
-			child.Finish(tracer.WithError(err))
-		} else {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
 		return err
 	}
 
@@ -204,6 +145,12 @@ func NewMinioService(name ServiceName, config map[string]any) (Service, error) {
 	return &minioService, nil
 }
 
+// Health lists the buckets
+func (ms *MinioService) Health(ctx context.Context) error {
+	_, err := ms.client.ListBuckets(ctx)
+	return err
+}
+
 func init() {
 	SERVICE_TYPES["minio"] = func(name ServiceName, m map[string]any) Service {
 		s, err := NewMinioService(name, m)
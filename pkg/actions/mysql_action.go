@@ -4,18 +4,16 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"sync"
 	"time"
 
 	"database/sql"
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"go.nhat.io/otelsql"
-	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.uber.org/zap"
 )
 
@@ -24,9 +22,6 @@ var MYDBQueryHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
 	Help: "The number of MySQL queries",
 })
 
-var sqlDbRegisterOnce sync.Once
-var sqlDriverName string
-
 type MysqlQuery struct{}
 
 type MysqlQueryConfig struct {
@@ -45,34 +40,14 @@ type MysqlQueryConfig struct {
 	PeerNamespace string `json:"peer_namespace"`
 }
 
-func openMysql(dsn string, host string, port int, dbname string) (*sql.DB, error) {
-	var dn string
-	var err error
-	sqlDbRegisterOnce.Do(func() {
-		// Register the otelsql wrapper for the provided mysql driver.
-		dn, err = otelsql.Register("mysql",
-			otelsql.AllowRoot(),
-			otelsql.TraceQueryWithoutArgs(),
-			otelsql.TraceRowsClose(),
-			otelsql.TraceRowsAffected(),
-			otelsql.WithDatabaseName(dbname),
-			otelsql.WithSystem(semconv.DBSystemMySQL),
-			otelsql.WithDefaultAttributes(
-				semconv.ServerAddress(host),
-				semconv.ServerPort(port),
-			),
-		)
-		if err == nil {
-			sqlDriverName = dn
-		}
+func openMysql(dsn string, host string, port int, dbname string, peerService string) (*sql.DB, error) {
+	return tracing.Get().OpenSQL("mysql", dsn, tracing.SQLOptions{
+		System:      "mysql",
+		Database:    dbname,
+		Host:        host,
+		Port:        port,
+		PeerService: peerService,
 	})
-
-	if sqlDriverName == "" || err != nil {
-		return nil, err
-	}
-
-	// Connect to a Mysql database using the mysql driver wrapper.
-	return sql.Open(sqlDriverName, dsn)
 }
 
 func (mysql *MysqlQuery) Execute(ctx context.Context, cfg map[string]any) error {
@@ -136,7 +111,7 @@ func (mysql *MysqlQuery) Execute(ctx context.Context, cfg map[string]any) error
 	//}
 	//db, err := sql.Open(MYDRIVER, mysqlConfig.FormatDSN())
 	connStr := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?tls=%s", user, password, host, strconv.Itoa(port), dbname, sslmode)
-	db, err := openMysql(connStr, host, port, dbname)
+	db, err := openMysql(connStr, host, port, dbname, config.PeerService)
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to connect to DB", zap.Error(err))
 		return err
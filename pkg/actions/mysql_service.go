@@ -128,7 +128,7 @@ func NewMysqlService(name ServiceName, config map[string]any) (Service, error) {
 	}
 
 	connStr := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?tls=%s", user, password, host, strconv.Itoa(port), dbname, sslmode)
-	db, err := openMysql(connStr, host, port, dbname)
+	db, err := openMysql(connStr, host, port, dbname, cfg.PeerService)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +147,11 @@ func (mysql *MysqlService) ParseConfig(data map[string]any) (any, error) {
 	return pkg.ParseConfig[MysqlServiceConfig](data)
 }
 
+// Health pings the database
+func (mysql *MysqlService) Health(ctx context.Context) error {
+	return mysql.db.PingContext(ctx)
+}
+
 func init() {
 	SERVICE_TYPES["mysql"] = func(name ServiceName, m map[string]any) Service {
 		s, err := NewMysqlService(name, m)
@@ -2,7 +2,6 @@ package actions
 
 import (
 	"context"
-	"math/rand"
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
@@ -24,7 +23,7 @@ func (a *Panic) Execute(ctx context.Context, cfg map[string]any) error {
 	}
 
 	if config.Probability > 0 {
-		if rand.Float64() < config.Probability {
+		if RandFromContext(ctx).Float64() < config.Probability {
 			go func() {
 				panic("Failed to execute action")
 			}()
@@ -0,0 +1,133 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Parallel executes its branches concurrently, like a service fanning out to its dependencies.
+// Branches should not write the HTTP response, their writes would race.
+type Parallel struct{}
+
+type ParallelConfig struct {
+	Branches []Workload `json:"branches"`
+	// Concurrency limits the number of branches executed at the same time, 0 for no limit
+	Concurrency int `json:"concurrency"`
+	// FailFast cancels the other branches once one fails, otherwise all branches complete
+	FailFast bool `json:"fail_fast"`
+}
+
+func (a *Parallel) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	if config.FailFast {
+		// The other branches stop before their next action
+		ctx = WithStopOnCancel(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := config.Concurrency
+	if concurrency == 0 {
+		concurrency = len(config.Branches)
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(config.Branches))
+
+	for i := range config.Branches {
+		// Every branch has its own generator, derived in order before the branches run
+		branchCtx := forkRand(ctx)
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[i] = branchCtx.Err()
+				return
+			}
+
+			errs[i] = executeBranch(branchCtx, i, &config.Branches[i])
+			if errs[i] != nil && config.FailFast {
+				cancel()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if config.FailFast {
+		// Report the failure that cancelled the other branches, not their cancellation
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// executeBranch executes a branch in its own span, so the spans of its actions are
+// children of the branch and show the fan-out
+func executeBranch(ctx context.Context, i int, branch *Workload) error {
+	name := fmt.Sprintf("branch %d", i+1)
+
+	ctx, span := tracing.StartSpan(ctx, "Parallel "+name, tracing.WithOperation("parallel.branch"))
+	ctx, done := explainAction(ctx, name)
+
+	err := branch.Execute(ctx)
+	done(err)
+	span.End(err)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (a *Parallel) parseConfig(data map[string]any) (*ParallelConfig, error) {
+	config, err := pkg.ParseConfig[ParallelConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must not be negative")
+	}
+
+	for i := range config.Branches {
+		err := config.Branches[i].Verify()
+		if err != nil {
+			return nil, fmt.Errorf("branch %d: %w", i+1, err)
+		}
+	}
+
+	return config, nil
+}
+
+func (a *Parallel) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["Parallel"] = &Parallel{}
+}
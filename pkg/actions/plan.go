@@ -24,10 +24,66 @@ type ActionConfig struct {
 }
 
 type Workers struct {
-	Instances uint          `json:"instances" yaml:"instances"`
-	Duration  time.Duration `json:"duration" yaml:"duration"`
-	Delay     time.Duration `json:"delay" yaml:"delay"`
-	Timeout   time.Duration `json:"timeout" yaml:"timeout"`
+	Instances uint               `json:"instances" yaml:"instances"`
+	Duration  time.Duration      `json:"duration" yaml:"duration"`
+	Delay     time.Duration      `json:"delay" yaml:"delay"`
+	Timeout   time.Duration      `json:"timeout" yaml:"timeout"`
+	Network   *NetworkConditions `json:"network" yaml:"network"`
+	Transport *TransportSettings `json:"transport" yaml:"transport"`
+}
+
+// TransportSettings controls how the client workers reuse connections to the server
+type TransportSettings struct {
+	DisableKeepAlives   bool `json:"disable_keep_alives" yaml:"disable_keep_alives"`
+	MaxIdleConns        int  `json:"max_idle_conns" yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int  `json:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host"`
+	// PerWorker gives every worker instance its own transport instead of one per worker group
+	PerWorker bool `json:"per_worker" yaml:"per_worker"`
+	// HTTP2 sends requests over unencrypted HTTP/2 instead of HTTP/1.1
+	HTTP2 bool `json:"http2" yaml:"http2"`
+	// NewConnectionEvery closes the connection of every Nth request, forcing a new one
+	NewConnectionEvery int `json:"new_connection_every" yaml:"new_connection_every"`
+}
+
+func (t *TransportSettings) Verify() error {
+	if t.MaxIdleConns < 0 || t.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("max idle connections must not be negative")
+	}
+	if t.NewConnectionEvery < 0 {
+		return fmt.Errorf("new_connection_every must not be negative")
+	}
+
+	return nil
+}
+
+// NetworkConditions emulates a bad network between the client workers and the server
+type NetworkConditions struct {
+	// Latency is added to every request, varied by +/- Jitter
+	Latency time.Duration `json:"latency" yaml:"latency"`
+	Jitter  time.Duration `json:"jitter" yaml:"jitter"`
+	// Bandwidth caps each connection in both directions, in bytes per second
+	Bandwidth int64 `json:"bandwidth" yaml:"bandwidth"`
+	// DropRate is the fraction of requests whose connection is closed after the request was sent
+	DropRate float64 `json:"drop_rate" yaml:"drop_rate"`
+	// FailRate is the fraction of requests that fail before being sent
+	FailRate float64 `json:"fail_rate" yaml:"fail_rate"`
+}
+
+func (n *NetworkConditions) Verify() error {
+	if n.Latency < 0 || n.Jitter < 0 {
+		return fmt.Errorf("latency and jitter must not be negative")
+	}
+	if n.Bandwidth < 0 {
+		return fmt.Errorf("bandwidth must not be negative")
+	}
+	if n.DropRate < 0 || n.DropRate > 1 {
+		return fmt.Errorf("drop_rate must be between 0 and 1")
+	}
+	if n.FailRate < 0 || n.FailRate > 1 {
+		return fmt.Errorf("fail_rate must be between 0 and 1")
+	}
+
+	return nil
 }
 
 type Phase struct {
@@ -41,6 +97,8 @@ type Phase struct {
 
 type Workload struct {
 	Actions []ActionConfig `yaml:"actions" json:"actions"`
+	// Async executes the workload as a background job, the server responds with the job ID
+	Async bool `yaml:"async" json:"async"`
 }
 
 type Client struct {
@@ -50,10 +108,23 @@ type Client struct {
 // Plan defines the structure of a chaos test plan
 type Plan struct {
 	Pattern PhasePattern `yaml:"pattern"`
-	Phases  []Phase      `yaml:"phases"`
+	// Setup runs once before the first phase, Teardown once after the last
+	Setup    Workload `yaml:"setup"`
+	Phases   []Phase  `yaml:"phases"`
+	Teardown Workload `yaml:"teardown"`
 }
 
 func (plan *Plan) Verify() error {
+	err := plan.Setup.Verify()
+	if err != nil {
+		return err
+	}
+
+	err = plan.Teardown.Verify()
+	if err != nil {
+		return err
+	}
+
 	for i, phase := range plan.Phases {
 		// Verify worker durations
 		for j, worker := range phase.Client.Workers {
@@ -68,6 +139,16 @@ func (plan *Plan) Verify() error {
 				return fmt.Errorf("phase %d worker %d: worker duration %v exceeds maximum allowed duration %v (this will be adjusted at runtime)",
 					i+1, j+1, worker.Duration, pkg.MaxPhaseDuration)
 			}
+			if worker.Network != nil {
+				if err := worker.Network.Verify(); err != nil {
+					return fmt.Errorf("phase %d worker %d: network: %w", i+1, j+1, err)
+				}
+			}
+			if worker.Transport != nil {
+				if err := worker.Transport.Verify(); err != nil {
+					return fmt.Errorf("phase %d worker %d: transport: %w", i+1, j+1, err)
+				}
+			}
 		}
 
 		err := phase.Verify()
@@ -114,6 +195,19 @@ func (phase *Phase) Verify() error {
 	return nil
 }
 
+const stopOnCancelKey ContextKey = "stop-on-cancel"
+
+// WithStopOnCancel makes workloads executed with ctx stop before their next action once
+// ctx is done. Otherwise workloads run to completion, also when their request is cancelled.
+func WithStopOnCancel(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stopOnCancelKey, true)
+}
+
+func stopsOnCancel(ctx context.Context) bool {
+	stop, _ := ctx.Value(stopOnCancelKey).(bool)
+	return stop
+}
+
 func (workload *Workload) Execute(ctx context.Context) error {
 	for _, action := range workload.Actions {
 		a := ACTIONS[action.Name]
@@ -124,12 +218,29 @@ func (workload *Workload) Execute(ctx context.Context) error {
 		}
 
 		if ctx.Err() != nil {
+			if stopsOnCancel(ctx) {
+				logger.FromContext(ctx).Warn("context error, stopping workload", zap.Error(ctx.Err()), zap.String("action", action.Name))
+				return ctx.Err()
+			}
+
 			logger.FromContext(ctx).Warn("context error", zap.Error(ctx.Err()), zap.String("action", action.Name))
 		}
 
 		logger.FromContext(ctx).Info("executing action", zap.String("action", action.Name), zap.Any("config", action.Config))
-		err = a.Execute(ctx, action.Config)
+		actionCtx, done := explainAction(ctx, action.Name)
+		actionCtx, peers := withPeerRecorder(actionCtx)
+		start := time.Now()
+		err = a.Execute(actionCtx, action.Config)
+		done(err)
+		recordAction(action.Name, err)
+
+		peer := peerService(action.Config)
+		if peer == "" {
+			peer = peers.get()
+		}
+		actionDuration.WithLabelValues(action.Name, peer).Observe(time.Since(start).Seconds())
 		if err != nil {
+			actionErrors.WithLabelValues(action.Name, peer).Inc()
 			logger.FromContext(ctx).Warn("action execution failed", zap.Error(err), zap.String("action", action.Name))
 			return err
 		}
@@ -3,7 +3,6 @@ package actions
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"database/sql"
@@ -12,12 +11,10 @@ import (
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"go.nhat.io/otelsql"
-	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.uber.org/zap"
-	sqltrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/database/sql"
 )
 
 var PQDBQueryHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
@@ -25,9 +22,6 @@ var PQDBQueryHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
 	Help: "The number of Postgres SQL queries",
 })
 
-var pgDbRegisterOnce sync.Once
-var pgDriverName string
-
 type PostgresqlQuery struct{}
 
 type PostgresqlQueryConfig struct {
@@ -46,33 +40,14 @@ type PostgresqlQueryConfig struct {
 	PeerNamespace string `json:"peer_namespace"`
 }
 
-func openPostgres(dsn string, host string, port int, dbname string) (*sql.DB, error) {
-	var dn string
-	var err error
-	pgDbRegisterOnce.Do(func() {
-		// Register the otelsql wrapper for the provided postgres driver.
-		dn, err = otelsql.Register("postgres",
-			otelsql.AllowRoot(),
-			otelsql.TraceQueryWithoutArgs(),
-			otelsql.TraceRowsClose(),
-			otelsql.TraceRowsAffected(),
-			otelsql.WithDatabaseName(dbname),               // Optional.
-			otelsql.WithSystem(semconv.DBSystemPostgreSQL), // Optional.
-			otelsql.WithDefaultAttributes(
-				semconv.ServerAddress(host),
-				semconv.ServerPort(port),
-			),
-		)
-		if err == nil {
-			pgDriverName = dn
-		}
+func openPostgres(dsn string, host string, port int, dbname string, peerService string) (*sql.DB, error) {
+	return tracing.Get().OpenSQL("postgres", dsn, tracing.SQLOptions{
+		System:      "postgresql",
+		Database:    dbname,
+		Host:        host,
+		Port:        port,
+		PeerService: peerService,
 	})
-	if pgDriverName == "" || err != nil {
-		return nil, err
-	}
-
-	// Connect to a Postgres database using the postgres driver wrapper.
-	return sql.Open(pgDriverName, dsn)
 }
 
 func (postgres *PostgresqlQuery) Execute(ctx context.Context, cfg map[string]any) error {
@@ -125,13 +100,7 @@ func (postgres *PostgresqlQuery) Execute(ctx context.Context, cfg map[string]any
 
 	connStr := fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=%s application_name=%s", host, port, user, dbname, password, sslmode, appname)
 
-	var db *sql.DB
-	if pkg.IsDatadogEnabled() {
-		db, err = sqltrace.Open("postgres", connStr, sqltrace.WithServiceName(config.PeerService))
-	} else {
-		db, err = openPostgres(connStr, host, port, dbname)
-	}
-
+	db, err := openPostgres(connStr, host, port, dbname, config.PeerService)
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to connect to DB", zap.Error(err))
 		return err
@@ -8,7 +8,6 @@ import (
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
 	"go.uber.org/zap"
-	sqltrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/database/sql"
 )
 
 type PostgresqlService struct {
@@ -130,13 +129,7 @@ func NewPostgresqlService(name ServiceName, config map[string]any) (Service, err
 
 	connStr := fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=%s application_name=%s", host, port, dbname, user, password, sslmode, appname)
 
-	var db *sql.DB
-	if pkg.IsDatadogEnabled() {
-		db, err = sqltrace.Open("postgres", connStr, sqltrace.WithServiceName(cfg.PeerService))
-	} else {
-		db, err = openPostgres(connStr, host, port, dbname)
-	}
-
+	db, err := openPostgres(connStr, host, port, dbname, cfg.PeerService)
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +141,11 @@ func NewPostgresqlService(name ServiceName, config map[string]any) (Service, err
 	return &postgresqlService, nil
 }
 
+// Health pings the database
+func (postgres *PostgresqlService) Health(ctx context.Context) error {
+	return postgres.db.PingContext(ctx)
+}
+
 func init() {
 	SERVICE_TYPES["postgresql"] = func(name ServiceName, m map[string]any) Service {
 		s, err := NewPostgresqlService(name, m)
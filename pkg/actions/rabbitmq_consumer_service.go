@@ -2,20 +2,19 @@ package actions
 
 import (
 	"context"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
+	"errors"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
-	oteltrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/streadway/amqp"
 	"go.uber.org/zap"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 type RabbitMQConsumerService struct {
+	ServiceStats
+
 	name   BackgroundServiceName
 	config *RabbitMQConsumerServiceConfig
 	conn   *amqp.Connection
@@ -54,27 +53,14 @@ func (consumer *RabbitMQConsumerService) getChannel() (*amqp.Channel, error) {
 	return consumer.conn.Channel()
 }
 
+// Run consumes until ctx is cancelled, it returns an error when the connection fails so it is restarted
 func (consumer *RabbitMQConsumerService) Run(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-
-		default:
-			err := consumer.run(ctx)
-			if err != nil {
-				logger.FromContext(ctx).Warn("failed to consume message", zap.Error(err))
-			}
-		}
-	}
-}
-
-func (consumer *RabbitMQConsumerService) run(ctx context.Context) error {
 	ch, err := consumer.getChannel()
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to get channel", zap.Error(err))
 		return err
 	}
+	defer ch.Close()
 
 	// Declare a queue for receiving
 	q, err := ch.QueueDeclare(
@@ -106,76 +92,44 @@ func (consumer *RabbitMQConsumerService) run(ctx context.Context) error {
 		return err
 	}
 
-	for msg := range deliveries {
-		err = consumer.handleMessage(ctx, msg)
-		if err != nil {
-			logger.FromContext(ctx).Warn("failed to handle message", zap.Error(err))
-		}
-	}
-
-	return nil
-}
-
-func (consumer *RabbitMQConsumerService) ddHandleMessage(ctx context.Context, msg amqp.Delivery) error {
-	span := tracer.StartSpan("handle_message",
-		tracer.ResourceName("handle_message"))
-	defer span.Finish()
-
-	// This span is just to show the relationship between the kafka consumer and the topic.
-	child := tracer.StartSpan("rabbitmq.consume",
-		tracer.ResourceName("Consume message"),
-		tracer.Tag("queue", consumer.config.Queue),      // Required tag
-		tracer.Tag("span.kind", "consumer"),             // Required tag
-		tracer.ServiceName(consumer.config.PeerService), // Required tag
-		tracer.ChildOf(span.Context()),
-	)
-	child.Finish()
-
-	err := tracer.Inject(span.Context(), ctx)
-	if err != nil {
-		ackErr := msg.Ack(false)
-		if ackErr != nil {
-			logger.FromContext(ctx).Warn("failed to ack message", zap.Error(ackErr))
-		}
-		return err
-	}
-
-	cfg := ScriptConfig{
-		Script:  consumer.config.Script,
-		Message: string(msg.Body),
-	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-deliveries:
+			if !ok {
+				return errors.New("delivery channel closed")
+			}
 
-	c, err := pkg.ConfigToMap(&cfg)
-	if err != nil {
-		ackErr := msg.Ack(false)
-		if ackErr != nil {
-			logger.FromContext(ctx).Warn("failed to ack message", zap.Error(ackErr))
+			// Finish handling the message when stopping
+			err = consumer.handleMessage(context.WithoutCancel(ctx), msg)
+			consumer.Inc("messages_consumed")
+			if err != nil {
+				consumer.Inc("message_errors")
+				consumer.SetError(err)
+				logger.FromContext(ctx).Warn("failed to handle message", zap.Error(err))
+			}
 		}
-		child.Finish(tracer.WithError(err))
-		return err
 	}
-
-	err = ACTIONS["Script"].Execute(ctx, c)
-	if err != nil {
-		logger.FromContext(ctx).Warn("failed to execute script", zap.Error(err))
-	}
-
-	return nil
 }
 
 func (consumer *RabbitMQConsumerService) handleMessage(ctx context.Context, msg amqp.Delivery) error {
-	if pkg.IsDatadogEnabled() {
-		return consumer.ddHandleMessage(ctx, msg)
+	// Continue the trace of the producer
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		if value, ok := v.(string); ok {
+			headers[k] = value
+		}
 	}
-	consumeTracer := otel.GetTracerProvider().Tracer("rabbitmq-consumer")
-	ctx, span := consumeTracer.Start(ctx, "Consume Queue "+consumer.config.Queue, oteltrace.WithSpanKind(oteltrace.SpanKindConsumer))
-	defer span.End()
-	span.SetAttributes(semconv.MessagingDestinationName(consumer.config.Queue))
-	span.SetAttributes(semconv.MessagingSystemRabbitmq)
+	ctx = tracing.Get().Extract(ctx, headers)
 
-	// https://opentelemetry.io/docs/specs/semconv/messaging/kafka/#:~:text=For%20Apache%20Kafka%20producers
-	span.SetAttributes(semconv.PeerService(consumer.config.PeerService))
-	span.SetAttributes(attribute.String("peer.namespace", consumer.config.PeerNamespace))
+	ctx, span := tracing.StartSpan(ctx, "Consume Queue "+consumer.config.Queue,
+		tracing.WithKind(tracing.KindConsumer),
+		tracing.WithOperation("rabbitmq.consume"),
+		tracing.WithPeer(consumer.config.PeerService, consumer.config.PeerNamespace),
+		tracing.WithAttribute(string(semconv.MessagingSystemKey), "rabbitmq"),
+		tracing.WithAttribute(string(semconv.MessagingDestinationNameKey), consumer.config.Queue),
+	)
 
 	cfg := ScriptConfig{
 		Script:  consumer.config.Script,
@@ -188,17 +142,17 @@ func (consumer *RabbitMQConsumerService) handleMessage(ctx context.Context, msg
 		if ackErr != nil {
 			logger.FromContext(ctx).Warn("failed to ack message", zap.Error(ackErr))
 		}
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+		span.End(err)
 		return err
-	} else {
-		span.SetStatus(codes.Ok, "message received")
 	}
 
 	err = ACTIONS["Script"].Execute(ctx, c)
 	if err != nil {
+		consumer.Inc("script_errors")
+		consumer.SetError(err)
 		logger.FromContext(ctx).Warn("failed to execute script", zap.Error(err))
 	}
+	span.End(err)
 
 	return nil
 }
@@ -3,16 +3,13 @@ package actions
 import (
 	"context"
 
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
-	oteltrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	"github.com/streadway/amqp"
 	"go.uber.org/zap"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 type RabbitMQProducerService struct {
@@ -70,7 +67,7 @@ func (producer *RabbitMQProducerService) Close() error {
 	return nil
 }
 
-func (producer *RabbitMQProducerService) ddProduce(ctx context.Context, queue string, msg string) error {
+func (producer *RabbitMQProducerService) Produce(ctx context.Context, queue string, msg string) (err error) {
 	ch, err := producer.getChannel()
 	if err != nil {
 		logger.FromContext(ctx).Warn("failed to get channel", zap.Error(err))
@@ -97,75 +94,22 @@ func (producer *RabbitMQProducerService) ddProduce(ctx context.Context, queue st
 		return err
 	}
 
-	span := tracer.StartSpan("rabbitmq.produce",
-		tracer.ResourceName("Produce message"),
-		tracer.Tag("queue", queue))
-	defer span.Finish()
-
-	child := tracer.StartSpan("rabbitmq.produce",
-		tracer.ResourceName("Produce message"),
-		tracer.Tag("queue", queue),
-		tracer.Tag("span.kind", "producer"),
-		tracer.ServiceName(producer.config.PeerService),
-		tracer.ChildOf(span.Context()),
+	ctx, span := tracing.StartSpan(ctx, "Produce Message",
+		tracing.WithKind(tracing.KindProducer),
+		tracing.WithOperation("rabbitmq.produce"),
+		tracing.WithPeer(producer.config.PeerService, producer.config.PeerNamespace),
+		tracing.WithAttribute(string(semconv.MessagingSystemKey), "rabbitmq"),
+		tracing.WithAttribute(string(semconv.MessagingDestinationNameKey), queue),
 	)
-	defer child.Finish()
+	defer func() {
+		span.End(err)
+	}()
 
-	// Send a message
-	return ch.Publish(
-		"",     // exchange
-		q.Name, // routing key
-		false,  // mandatory
-		false,  // immediate
-		amqp.Publishing{
-			ContentType: "text/plain",
-			Body:        []byte(msg),
-		},
-	)
-}
-
-func (producer *RabbitMQProducerService) Produce(ctx context.Context, queue string, msg string) error {
-	if pkg.IsDatadogEnabled() {
-		return producer.ddProduce(ctx, queue, msg)
-	}
-	ch, err := producer.getChannel()
-	if err != nil {
-		logger.FromContext(ctx).Warn("failed to get channel", zap.Error(err))
-		return err
-	}
-
-	// Declare a queue for sending
-	q, err := ch.QueueDeclare(
-		queue, // name
-		false, // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		nil,   // arguments
-	)
-
-	if err != nil {
-		logger.FromContext(ctx).Warn("failed to declare queue", zap.Error(err))
-
-		if err := producer.Close(); err != nil {
-			logger.FromContext(ctx).Warn("failed to close connection", zap.Error(err))
-		}
-
-		return err
+	headers := amqp.Table{}
+	for k, v := range tracing.Get().Inject(ctx) {
+		headers[k] = v
 	}
 
-	prodTracer := otel.GetTracerProvider().Tracer("kafka-producer")
-
-	_, span := prodTracer.Start(ctx, "Produce Message", oteltrace.WithSpanKind(oteltrace.SpanKindProducer))
-	defer span.End()
-
-	span.SetAttributes(semconv.MessagingDestinationName(queue))
-	span.SetAttributes(semconv.MessagingSystemRabbitmq)
-
-	// https://opentelemetry.io/docs/specs/semconv/messaging/kafka/#:~:text=For%20Apache%20Kafka%20producers
-	span.SetAttributes(semconv.PeerService(producer.config.PeerService))
-	span.SetAttributes(attribute.String("peer.namespace", producer.config.PeerNamespace))
-
 	// Send a message
 	return ch.Publish(
 		"",     // exchange
@@ -173,6 +117,7 @@ func (producer *RabbitMQProducerService) Produce(ctx context.Context, queue stri
 		false,  // mandatory
 		false,  // immediate
 		amqp.Publishing{
+			Headers:     headers,
 			ContentType: "text/plain",
 			Body:        []byte(msg),
 		},
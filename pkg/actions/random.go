@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SeedHeader seeds the random decisions of a request, so they can be reproduced
+const SeedHeader = "X-Chaosmania-Seed"
+
+const randKey ContextKey = "rand"
+
+// Rand is the random number generator of a request, safe for concurrent use by parallel actions
+type Rand struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+	// seeded is set if the seed was requested, it is then passed on to downstream servers
+	seeded bool
+}
+
+func (r *Rand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rand.Float64()
+}
+
+func (r *Rand) Int63() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rand.Int63()
+}
+
+func (r *Rand) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rand.Int63n(n)
+}
+
+func (r *Rand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rand.Intn(n)
+}
+
+// NewRandContext seeds the random number generator of the request from its SeedHeader,
+// or randomly if it has none. A generator already in ctx is kept, so fault injection and
+// actions draw from the same sequence.
+func NewRandContext(ctx context.Context, header http.Header) context.Context {
+	if _, ok := ctx.Value(randKey).(*Rand); ok {
+		return ctx
+	}
+
+	r := &Rand{}
+
+	seed, err := strconv.ParseInt(header.Get(SeedHeader), 10, 64)
+	if err == nil {
+		r.seeded = true
+	} else {
+		seed = time.Now().UnixNano()
+	}
+	r.rand = rand.New(rand.NewSource(seed))
+
+	return context.WithValue(ctx, randKey, r)
+}
+
+// RandFromContext returns the random number generator of the request, or a randomly seeded one
+func RandFromContext(ctx context.Context) *Rand {
+	if r, ok := ctx.Value(randKey).(*Rand); ok {
+		return r
+	}
+
+	return &Rand{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// forkRand gives ctx a generator seeded from the one of the request, so actions running
+// concurrently draw reproducible sequences regardless of their scheduling
+func forkRand(ctx context.Context) context.Context {
+	r := RandFromContext(ctx)
+	return context.WithValue(ctx, randKey, &Rand{
+		rand:   rand.New(rand.NewSource(r.Int63())),
+		seeded: r.seeded,
+	})
+}
+
+// setDownstreamSeed derives the seed of a downstream request from a requested seed,
+// so the random decisions of the whole call tree are reproduced
+func setDownstreamSeed(ctx context.Context, header http.Header) {
+	r, ok := ctx.Value(randKey).(*Rand)
+	if !ok || !r.seeded {
+		return
+	}
+
+	header.Set(SeedHeader, strconv.FormatInt(r.Int63(), 10))
+}
@@ -2,7 +2,6 @@ package actions
 
 import (
 	"context"
-	"math/rand"
 	"os"
 
 	"github.com/Causely/chaosmania/pkg"
@@ -46,11 +45,12 @@ func (a *RandomIO) Execute(ctx context.Context, cfg map[string]any) error {
 		return err
 	}
 
+	rng := RandFromContext(ctx)
 	buffer := make([]byte, config.BlockSize)
 	for i := 0; i < config.IoCount; i++ {
-		offset := rand.Int63n(config.FileSize/config.BlockSize) * config.BlockSize
+		offset := rng.Int63n(config.FileSize/config.BlockSize) * config.BlockSize
 
-		if rand.Float32() < config.ReadPercentage {
+		if float32(rng.Float64()) < config.ReadPercentage {
 			_, err = f.ReadAt(buffer, offset)
 			if err != nil {
 				logger.FromContext(ctx).Warn("failed to read from file", zap.Error(err))
@@ -7,11 +7,9 @@ import (
 
 	"github.com/Causely/chaosmania/pkg"
 	"github.com/Causely/chaosmania/pkg/logger"
-	redis8 "github.com/go-redis/redis/v8"
-	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	redis9 "github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
-	redistrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/go-redis/redis.v8"
 )
 
 type RedisCommand struct{}
@@ -31,60 +29,32 @@ func (redis *RedisCommand) Execute(ctx context.Context, cfg map[string]any) erro
 		return err
 	}
 
-	if pkg.IsDatadogEnabled() {
-		opts := &redis8.Options{Addr: config.Address, DB: 0}
-		rdb := redistrace.NewClient(opts, redistrace.WithServiceName(config.PeerService))
+	rdb := redis9.NewClient(&redis9.Options{
+		Addr: config.Address,
+		DB:   0,
+	})
+	defer rdb.Close()
 
-		switch strings.ToLower(config.Command) {
-		case "lpop":
-			err = rdb.LPop(ctx, config.Args[0].(string)).Err()
-		case "lpush":
-			err = rdb.LPush(ctx, config.Args[0].(string), config.Args[:1]...).Err()
-		case "get":
-			err = rdb.Get(ctx, config.Args[0].(string)).Err()
-		case "set":
-			err = rdb.Set(ctx, config.Args[0].(string), config.Args[1].(string), 0).Err()
-		default:
-			return fmt.Errorf("redis command not supported: %s", config.Command)
-		}
-
-		if err != nil && err != redis8.Nil {
-			return err
-		}
-	} else {
-		opts := &redis9.Options{
-			Addr: config.Address,
-			DB:   0,
-		}
-		rdb := redis9.NewClient(opts)
-
-		// Enable opentelemetry for redis
-		err := redisotel.InstrumentMetrics(rdb)
-		if err != nil {
-			logger.FromContext(ctx).Error("failed to enable redis opentelemetry metrics", zap.Error(err))
-		}
-		err = redisotel.InstrumentTracing(rdb)
-		if err != nil {
-			logger.FromContext(ctx).Error("failed to enable redis opentelemetry tracing", zap.Error(err))
-		}
-		defer rdb.Close()
+	err = tracing.Get().InstrumentRedis(rdb, config.PeerService)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to instrument redis client", zap.Error(err))
+	}
 
-		switch strings.ToLower(config.Command) {
-		case "lpop":
-			err = rdb.LPop(ctx, config.Args[0].(string)).Err()
-		case "lpush":
-			err = rdb.LPush(ctx, config.Args[0].(string), config.Args[:1]...).Err()
-		case "get":
-			err = rdb.Get(ctx, config.Args[0].(string)).Err()
-		case "set":
-			err = rdb.Set(ctx, config.Args[0].(string), config.Args[1].(string), 0).Err()
-		default:
-			return fmt.Errorf("redis command not supported: %s", config.Command)
-		}
+	switch strings.ToLower(config.Command) {
+	case "lpop":
+		err = rdb.LPop(ctx, config.Args[0].(string)).Err()
+	case "lpush":
+		err = rdb.LPush(ctx, config.Args[0].(string), config.Args[:1]...).Err()
+	case "get":
+		err = rdb.Get(ctx, config.Args[0].(string)).Err()
+	case "set":
+		err = rdb.Set(ctx, config.Args[0].(string), config.Args[1].(string), 0).Err()
+	default:
+		return fmt.Errorf("redis command not supported: %s", config.Command)
+	}
 
-		if err != nil && err != redis9.Nil {
-			return err
-		}
+	if err != nil && err != redis9.Nil {
+		return err
 	}
 
 	return nil
@@ -4,17 +4,14 @@ import (
 	"context"
 
 	"github.com/Causely/chaosmania/pkg"
-	redis8 "github.com/go-redis/redis/v8"
-	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/Causely/chaosmania/pkg/tracing"
 	redis9 "github.com/redis/go-redis/v9"
-	redistrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/go-redis/redis.v8"
 )
 
 type RedisService struct {
 	name   ServiceName
 	config RedisServiceConfig
-	rdb8   redis8.UniversalClient
-	rdb9   *redis9.Client
+	rdb    *redis9.Client
 }
 
 type RedisServiceConfig struct {
@@ -42,50 +39,34 @@ func NewRedisService(name ServiceName, config map[string]any) (Service, error) {
 		name:   name,
 	}
 
-	if pkg.IsDatadogEnabled() {
-		opts := &redis8.Options{Addr: cfg.Address, DB: 0}
-		rdb := redistrace.NewClient(opts, redistrace.WithServiceName(cfg.PeerService))
-		redisService.rdb8 = rdb
-	} else {
-		opts := &redis9.Options{
-			Addr: cfg.Address,
-			DB:   0,
-		}
-		rdb := redis9.NewClient(opts)
-
-		// Enable opentelemetry traces for redis
-		err := redisotel.InstrumentMetrics(rdb)
-		if err != nil {
-			return nil, err
-		}
-
-		err = redisotel.InstrumentTracing(rdb)
-		if err != nil {
-			return nil, err
-		}
+	rdb := redis9.NewClient(&redis9.Options{
+		Addr: cfg.Address,
+		DB:   0,
+	})
 
-		redisService.rdb9 = rdb
+	err = tracing.Get().InstrumentRedis(rdb, cfg.PeerService)
+	if err != nil {
+		return nil, err
 	}
 
+	redisService.rdb = rdb
+
 	return &redisService, nil
 }
 
 // Redis Set
 func (redis *RedisService) Set(ctx context.Context, key string, value string) error {
-	if redis.rdb8 != nil {
-		return redis.rdb8.Set(ctx, key, value, 0).Err()
-	}
-
-	return redis.rdb9.Set(ctx, key, value, 0).Err()
+	return redis.rdb.Set(ctx, key, value, 0).Err()
 }
 
 // Redis Get
 func (redis *RedisService) Get(ctx context.Context, key string) (string, error) {
-	if redis.rdb8 != nil {
-		return redis.rdb8.Get(ctx, key).Result()
-	}
+	return redis.rdb.Get(ctx, key).Result()
+}
 
-	return redis.rdb9.Get(ctx, key).Result()
+// Health pings the redis server
+func (redis *RedisService) Health(ctx context.Context) error {
+	return redis.rdb.Ping(ctx).Err()
 }
 
 func init() {
@@ -0,0 +1,35 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ReleaseFileDescriptors closes all descriptors leaked by LeakFileDescriptors
+type ReleaseFileDescriptors struct{}
+
+type ReleaseFileDescriptorsConfig struct{}
+
+func (a *ReleaseFileDescriptors) Execute(ctx context.Context, cfg map[string]any) error {
+	_, err := pkg.ParseConfig[ReleaseFileDescriptorsConfig](cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	released := LEAKED_FILE_DESCRIPTORS.Release()
+	logger.FromContext(ctx).Info("released leaked file descriptors", zap.Int("descriptors", released))
+
+	return nil
+}
+
+func (a *ReleaseFileDescriptors) ParseConfig(data map[string]any) (any, error) {
+	return pkg.ParseConfig[ReleaseFileDescriptorsConfig](data)
+}
+
+func init() {
+	ACTIONS["ReleaseFileDescriptors"] = &ReleaseFileDescriptors{}
+}
@@ -0,0 +1,35 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ReleaseGoroutines lets all goroutines leaked by LeakGoroutines return
+type ReleaseGoroutines struct{}
+
+type ReleaseGoroutinesConfig struct{}
+
+func (a *ReleaseGoroutines) Execute(ctx context.Context, cfg map[string]any) error {
+	_, err := pkg.ParseConfig[ReleaseGoroutinesConfig](cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	released := LEAKED_GOROUTINES.Release()
+	logger.FromContext(ctx).Info("released leaked goroutines", zap.Int("goroutines", released))
+
+	return nil
+}
+
+func (a *ReleaseGoroutines) ParseConfig(data map[string]any) (any, error) {
+	return pkg.ParseConfig[ReleaseGoroutinesConfig](data)
+}
+
+func init() {
+	ACTIONS["ReleaseGoroutines"] = &ReleaseGoroutines{}
+}
@@ -0,0 +1,44 @@
+package actions
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ReleaseMemory frees the memory leaked by AllocateMemory and stops its growth
+type ReleaseMemory struct{}
+
+type ReleaseMemoryConfig struct {
+	// FreeOSMemory returns the freed memory to the operating system right away, instead
+	// of at the next garbage collections
+	FreeOSMemory bool `json:"freeOSMemory"`
+}
+
+func (a *ReleaseMemory) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := pkg.ParseConfig[ReleaseMemoryConfig](cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	released := LEAKED_MEMORY.Release()
+	if config.FreeOSMemory {
+		debug.FreeOSMemory()
+	}
+
+	logger.FromContext(ctx).Info("released leaked memory", zap.Int("bytes", released))
+
+	return nil
+}
+
+func (a *ReleaseMemory) ParseConfig(data map[string]any) (any, error) {
+	return pkg.ParseConfig[ReleaseMemoryConfig](data)
+}
+
+func init() {
+	ACTIONS["ReleaseMemory"] = &ReleaseMemory{}
+}
@@ -0,0 +1,206 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Retry executes its actions again when they fail with a retryable error, like a
+// client retrying its requests. Every attempt is a child span of the current one.
+type Retry struct{}
+
+type RetryConfig struct {
+	Actions     []ActionConfig `json:"actions"`
+	MaxAttempts int            `json:"max_attempts"`
+	// Backoff is the delay before the second attempt, multiplied by Multiplier for
+	// every further attempt up to MaxBackoff
+	Backoff    pkg.Duration `json:"backoff"`
+	MaxBackoff pkg.Duration `json:"max_backoff"`
+	Multiplier float64      `json:"multiplier"`
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1
+	Jitter float64 `json:"jitter"`
+	// AttemptTimeout cancels an attempt taking longer, 0 for no timeout
+	AttemptTimeout pkg.Duration `json:"attempt_timeout"`
+	// RetryOn lists the retryable errors: error (any), timeout, connection, 4xx, 5xx or
+	// an HTTP status code like 429
+	RetryOn []string `json:"retry_on"`
+}
+
+var defaultRetryOn = []string{"connection", "timeout", "5xx"}
+
+func (a *Retry) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	workload := Workload{Actions: config.Actions}
+	delay := config.Backoff.Duration
+
+	for attempt := 1; ; attempt++ {
+		err = executeAttempt(ctx, attempt, config.AttemptTimeout.Duration, &workload)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if !isRetryable(err, config.RetryOn) {
+			return err
+		}
+
+		if attempt == config.MaxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		// Randomize the delay by up to the jitter fraction, so clients don't retry in lockstep
+		wait := delay - time.Duration(config.Jitter*RandFromContext(ctx).Float64()*float64(delay))
+		logger.FromContext(ctx).Info("retrying failed attempt", zap.Error(err), zap.Int("attempt", attempt), zap.Duration("backoff", wait))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		delay = min(time.Duration(float64(delay)*config.Multiplier), config.MaxBackoff.Duration)
+	}
+}
+
+func executeAttempt(ctx context.Context, attempt int, timeout time.Duration, workload *Workload) error {
+	name := fmt.Sprintf("attempt %d", attempt)
+
+	ctx, span := tracing.StartSpan(ctx, "Retry "+name,
+		tracing.WithOperation("retry.attempt"),
+		tracing.WithAttribute("retry.attempt", attempt),
+	)
+	ctx, done := explainAction(ctx, name)
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(WithStopOnCancel(ctx), timeout)
+		defer cancel()
+	}
+
+	err := workload.Execute(ctx)
+	done(err)
+	span.End(err)
+
+	return err
+}
+
+// isRetryable reports whether err matches one of the retryable errors
+func isRetryable(err error, retryOn []string) bool {
+	var statusErr *HTTPStatusError
+	isStatus := errors.As(err, &statusErr)
+
+	for _, retryable := range retryOn {
+		switch retryable {
+		case "error":
+			return true
+		case "timeout":
+			var netErr net.Error
+			if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+				return true
+			}
+		case "connection":
+			if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+				return true
+			}
+		case "4xx":
+			if isStatus && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 {
+				return true
+			}
+		case "5xx":
+			if isStatus && statusErr.StatusCode >= 500 {
+				return true
+			}
+		default:
+			if isStatus && strconv.Itoa(statusErr.StatusCode) == retryable {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *Retry) parseConfig(data map[string]any) (*RetryConfig, error) {
+	config, err := pkg.ParseConfig[RetryConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = 3
+	}
+	if config.Backoff.Duration == 0 {
+		config.Backoff.Duration = 100 * time.Millisecond
+	}
+	if config.MaxBackoff.Duration == 0 {
+		config.MaxBackoff.Duration = 10 * time.Second
+	}
+	if config.Multiplier == 0 {
+		config.Multiplier = 2
+	}
+	if len(config.RetryOn) == 0 {
+		config.RetryOn = defaultRetryOn
+	}
+
+	if config.MaxAttempts < 0 {
+		return nil, fmt.Errorf("max_attempts must not be negative")
+	}
+	if config.Backoff.Duration < 0 || config.MaxBackoff.Duration < 0 || config.AttemptTimeout.Duration < 0 {
+		return nil, fmt.Errorf("backoff, max_backoff and attempt_timeout must not be negative")
+	}
+	if config.Multiplier < 1 {
+		return nil, fmt.Errorf("multiplier must be at least 1")
+	}
+	if config.Jitter < 0 || config.Jitter > 1 {
+		return nil, fmt.Errorf("jitter must be between 0 and 1")
+	}
+
+	for _, retryable := range config.RetryOn {
+		switch retryable {
+		case "error", "timeout", "connection", "4xx", "5xx":
+		default:
+			if !validStatus(retryable) {
+				return nil, fmt.Errorf("invalid retryable error: %s. Must be one of: error, timeout, connection, 4xx, 5xx or a status code", retryable)
+			}
+		}
+	}
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func validStatus(status string) bool {
+	code, err := strconv.Atoi(status)
+	return err == nil && code >= 100 && code <= 599
+}
+
+func (a *Retry) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["Retry"] = &Retry{}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"math/rand"
 	"time"
 
 	"github.com/Causely/chaosmania/pkg"
@@ -61,6 +60,7 @@ func (sc *ScriptContext) Get_service(name string) (Service, error) {
 		return nil, err
 	}
 
+	recordPeer(sc.Ctx, ServiceName(name))
 	return service, nil
 }
 
@@ -71,9 +71,10 @@ func (sc *ScriptContext) Uuid() string {
 func (sc *ScriptContext) Random_string(n int64) string {
 	const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
+	rng := RandFromContext(sc.Ctx)
 	b := make([]byte, n)
 	for i := range b {
-		b[i] = letterBytes[rand.Intn(len(letterBytes))]
+		b[i] = letterBytes[rng.Intn(len(letterBytes))]
 	}
 	return string(b)
 }
@@ -33,11 +33,14 @@ type Services struct {
 
 type ServiceManager struct {
 	services map[ServiceName]Service
+	// configs are the declared configs of the services loaded from a file
+	configs map[ServiceName]map[string]any
 }
 
 func NewServiceManager() *ServiceManager {
 	return &ServiceManager{
 		services: make(map[ServiceName]Service),
+		configs:  make(map[ServiceName]map[string]any),
 	}
 }
 
@@ -59,6 +62,15 @@ func (sm *ServiceManager) Get(name ServiceName) (Service, error) {
 	return sm.services[name], nil
 }
 
+// PeerService returns the peer_service declared for a service, or its name
+func (sm *ServiceManager) PeerService(name ServiceName) string {
+	if peer, ok := sm.configs[name]["peer_service"].(string); ok && peer != "" {
+		return peer
+	}
+
+	return string(name)
+}
+
 func (sm *ServiceManager) LoadFromFile(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -82,6 +94,8 @@ func (sm *ServiceManager) LoadFromFile(path string) error {
 		if err != nil {
 			return err
 		}
+
+		sm.configs[service.Name] = service.Config
 	}
 
 	return nil
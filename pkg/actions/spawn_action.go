@@ -0,0 +1,108 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// spawnedTasks is the number of spawned tasks started or waiting to start
+var spawnedTasks atomic.Int64
+
+// Spawn executes its actions in the background and returns immediately, like a service
+// handing work to a goroutine. The actions outlive the request: they are not cancelled
+// with it, cannot write the response and are traced in a new trace linked to the request.
+type Spawn struct{}
+
+type SpawnConfig struct {
+	Actions []ActionConfig `json:"actions"`
+	// Delay is waited before the actions are started
+	Delay pkg.Duration `json:"delay"`
+	// MaxPending rejects new tasks while this many spawned tasks are outstanding
+	MaxPending int64 `json:"max_pending"`
+}
+
+func (a *Spawn) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	if spawnedTasks.Add(1) > config.MaxPending {
+		spawnedTasks.Add(-1)
+		spawnedTasksRejected.Inc()
+		return fmt.Errorf("too many pending spawned tasks, max %d", config.MaxPending)
+	}
+	spawnedTasksPending.Inc()
+
+	// Detach from the request: keep its values, but not its cancellation, response
+	// writer or execution tree, which are done once the request completes
+	detached := context.WithoutCancel(ctx)
+	detached = context.WithValue(detached, ResponseWriterKey, nil)
+	detached = context.WithValue(detached, explainKey, nil)
+	detached = forkRand(detached)
+
+	workload := Workload{Actions: config.Actions}
+	go func() {
+		defer func() {
+			spawnedTasks.Add(-1)
+			spawnedTasksPending.Dec()
+		}()
+
+		if config.Delay.Duration > 0 {
+			time.Sleep(config.Delay.Duration)
+		}
+
+		spawnCtx, span := tracing.StartSpan(detached, "Spawn",
+			tracing.WithOperation("spawn"),
+			tracing.WithLinkFrom(ctx),
+		)
+
+		err := workload.Execute(spawnCtx)
+		span.End(err)
+
+		if err != nil {
+			logger.FromContext(spawnCtx).Warn("spawned task failed", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (a *Spawn) parseConfig(data map[string]any) (*SpawnConfig, error) {
+	config, err := pkg.ParseConfig[SpawnConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.MaxPending == 0 {
+		config.MaxPending = 1000
+	}
+
+	if config.MaxPending < 0 || config.Delay.Duration < 0 {
+		return nil, fmt.Errorf("max_pending and delay must not be negative")
+	}
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (a *Spawn) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["Spawn"] = &Spawn{}
+}
@@ -0,0 +1,163 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the service whenever it returns
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the service when it returns an error or panics
+	RestartOnFailure RestartPolicy = "on-failure"
+	RestartNever     RestartPolicy = "never"
+)
+
+func (p RestartPolicy) Verify() error {
+	switch p {
+	case RestartAlways, RestartOnFailure, RestartNever:
+		return nil
+	default:
+		return fmt.Errorf("invalid restart policy: %s. Must be one of: always, on-failure, never", p)
+	}
+}
+
+func (p RestartPolicy) restarts(err error) bool {
+	return p == RestartAlways || (p == RestartOnFailure && err != nil)
+}
+
+const (
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+)
+
+// Backoff is the delay before restarting a service, doubled after each restart up to Max
+type Backoff struct {
+	Initial time.Duration `yaml:"initial"`
+	Max     time.Duration `yaml:"max"`
+}
+
+func (b Backoff) Verify() error {
+	if b.Initial < 0 || b.Max < 0 {
+		return fmt.Errorf("backoff must not be negative")
+	}
+
+	if b.Max > 0 && b.Initial > b.Max {
+		return fmt.Errorf("initial backoff must not be greater than the max backoff")
+	}
+
+	return nil
+}
+
+func (b Backoff) initial() time.Duration {
+	if b.Initial == 0 {
+		return min(defaultInitialBackoff, b.max())
+	}
+
+	return b.Initial
+}
+
+func (b Backoff) max() time.Duration {
+	if b.Max == 0 {
+		return max(defaultMaxBackoff, b.Initial)
+	}
+
+	return b.Max
+}
+
+// supervise runs the service and restarts it according to its policy until ctx is cancelled
+func (m *ManagedBackgroundService) supervise(ctx context.Context) {
+	name, serviceType := string(m.Service.Name()), string(m.Service.Type())
+	log := logger.FromContext(ctx).With(zap.String("service", name), zap.String("type", serviceType))
+	delay := m.Backoff.initial()
+
+	for {
+		started := time.Now()
+		m.setStatus(BackgroundServiceRunning, &started)
+		backgroundServicesUp.WithLabelValues(name, serviceType).Set(1)
+
+		err := m.runOnce(ctx)
+		backgroundServicesUp.WithLabelValues(name, serviceType).Set(0)
+
+		if ctx.Err() != nil {
+			log.Info("background service stopped")
+			m.setStatus(BackgroundServiceStopped, nil)
+			return
+		}
+
+		if err != nil {
+			m.setError(err)
+			log.Warn("background service failed", zap.Error(err))
+		} else {
+			log.Warn("background service returned")
+		}
+
+		if !m.Restart.restarts(err) {
+			if err != nil {
+				m.setStatus(BackgroundServiceFailed, nil)
+			} else {
+				m.setStatus(BackgroundServiceStopped, nil)
+			}
+			return
+		}
+
+		// A service that ran for longer than the max backoff was healthy, so it is restarted quickly again
+		if time.Since(started) > m.Backoff.max() {
+			delay = m.Backoff.initial()
+		}
+
+		m.setStatus(BackgroundServiceRestarting, nil)
+		log.Info("restarting background service", zap.Duration("backoff", delay))
+
+		select {
+		case <-ctx.Done():
+			m.setStatus(BackgroundServiceStopped, nil)
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, m.Backoff.max())
+
+		m.mu.Lock()
+		m.restarts++
+		m.mu.Unlock()
+		backgroundServiceRestarts.WithLabelValues(name, serviceType).Inc()
+	}
+}
+
+// runOnce runs the service, turning a panic into an error
+func (m *ManagedBackgroundService) runOnce(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return m.Service.Run(ctx)
+}
+
+// setStatus updates the status, and the start time if started is not nil
+func (m *ManagedBackgroundService) setStatus(status string, started *time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.status = status
+	if started != nil {
+		m.started = started
+	}
+}
+
+func (m *ManagedBackgroundService) setError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.lastError = err.Error()
+	m.lastErrorAt = &now
+}
@@ -0,0 +1,130 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Timeout fails once its actions take longer than the duration. Actions ignoring the
+// deadline, like a blocked GlobalMutexLock, continue in the background but no further
+// action of the list is started. They can no longer write the response.
+type Timeout struct{}
+
+type TimeoutConfig struct {
+	Duration pkg.Duration   `json:"duration"`
+	Actions  []ActionConfig `json:"actions"`
+}
+
+func (a *Timeout) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(WithStopOnCancel(ctx), config.Duration.Duration)
+	defer cancel()
+
+	// The handler may have returned by the time abandoned actions write the response
+	if w, ok := ctx.Value(ResponseWriterKey).(http.ResponseWriter); ok {
+		writer := &detachableWriter{w: w}
+		defer writer.detach()
+		ctx = context.WithValue(ctx, ResponseWriterKey, writer)
+	}
+
+	workload := Workload{Actions: config.Actions}
+	done := make(chan error, 1)
+	go func() {
+		done <- workload.Execute(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %v: %w", config.Duration.Duration, ctx.Err())
+		}
+		return err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %v: %w", config.Duration.Duration, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
+
+// detachableWriter passes writes to the response until it is detached, later writes
+// are dropped
+type detachableWriter struct {
+	mu       sync.Mutex
+	w        http.ResponseWriter
+	detached bool
+}
+
+func (d *detachableWriter) detach() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.detached = true
+}
+
+func (d *detachableWriter) Header() http.Header {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.detached {
+		return http.Header{}
+	}
+	return d.w.Header()
+}
+
+func (d *detachableWriter) Write(b []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.detached {
+		return 0, http.ErrHandlerTimeout
+	}
+	return d.w.Write(b)
+}
+
+func (d *detachableWriter) WriteHeader(statusCode int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.detached {
+		d.w.WriteHeader(statusCode)
+	}
+}
+
+func (a *Timeout) parseConfig(data map[string]any) (*TimeoutConfig, error) {
+	config, err := pkg.ParseConfig[TimeoutConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (a *Timeout) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["Timeout"] = &Timeout{}
+}
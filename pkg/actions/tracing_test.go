@@ -0,0 +1,156 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Causely/chaosmania/pkg/logger"
+	"github.com/Causely/chaosmania/pkg/tracing"
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+)
+
+// useRecorder installs a Recorder as the tracing provider for the test
+func useRecorder(t *testing.T) *tracing.Recorder {
+	t.Helper()
+
+	previous := tracing.Get()
+	recorder := tracing.NewRecorder()
+	tracing.SetProvider(recorder)
+	t.Cleanup(func() {
+		tracing.SetProvider(previous)
+	})
+
+	return recorder
+}
+
+func testContext() context.Context {
+	return logger.NewContext(context.Background(), zap.NewNop())
+}
+
+func findSpan(t *testing.T, spans []tracing.RecordedSpan, match func(tracing.RecordedSpan) bool) tracing.RecordedSpan {
+	t.Helper()
+
+	for _, span := range spans {
+		if match(span) {
+			return span
+		}
+	}
+
+	t.Fatalf("no matching span in %+v", spans)
+	return tracing.RecordedSpan{}
+}
+
+func named(name string) func(tracing.RecordedSpan) bool {
+	return func(span tracing.RecordedSpan) bool {
+		return span.Name == name
+	}
+}
+
+func TestHTTPRequestPropagation(t *testing.T) {
+	recorder := useRecorder(t)
+
+	server := httptest.NewServer(recorder.WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		func(r *http.Request) bool { return false },
+	))
+	defer server.Close()
+
+	ctx, root := tracing.StartSpan(testContext(), "request")
+	err := ACTIONS["HTTPRequest"].Execute(ctx, map[string]any{"url": server.URL + "/checkout"})
+	root.End(err)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Spans()
+	rootSpan := findSpan(t, spans, named("request"))
+	clientSpan := findSpan(t, spans, named("HTTP POST"))
+	serverSpan := findSpan(t, spans, named("/checkout"))
+
+	if clientSpan.ParentID != rootSpan.ID {
+		t.Errorf("expected the client span to be a child of %d, got %d", rootSpan.ID, clientSpan.ParentID)
+	}
+	if serverSpan.ParentID != clientSpan.ID {
+		t.Errorf("expected the server span to continue %d, got %d", clientSpan.ID, serverSpan.ParentID)
+	}
+}
+
+func TestKafkaPropagation(t *testing.T) {
+	recorder := useRecorder(t)
+
+	var sent *sarama.ProducerMessage
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		sent = msg
+		return nil
+	})
+
+	service := &KafkaProducerService{name: "kafka", producer: producer}
+	err := service.Produce(testContext(), "orders", "order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &sarama.ConsumerMessage{Topic: "orders", Value: []byte("order")}
+	for _, header := range sent.Headers {
+		h := header
+		msg.Headers = append(msg.Headers, &sarama.RecordHeader{Key: h.Key, Value: h.Value})
+	}
+
+	consumer := &KafkaConsumerService{config: &KafkaConsumerServiceConfig{
+		Topic:  "orders",
+		Group:  "shipping",
+		Script: "function run() {}",
+	}}
+	err = consumer.handleMessage(testContext(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Spans()
+	produceSpan := findSpan(t, spans, named("Produce Message"))
+	consumeSpan := findSpan(t, spans, named("Consume Topic orders"))
+
+	if produceSpan.Config.Kind != tracing.KindProducer || consumeSpan.Config.Kind != tracing.KindConsumer {
+		t.Errorf("unexpected span kinds %v and %v", produceSpan.Config.Kind, consumeSpan.Config.Kind)
+	}
+	if consumeSpan.ParentID != produceSpan.ID {
+		t.Errorf("expected the consume span to continue %d, got %d", produceSpan.ID, consumeSpan.ParentID)
+	}
+}
+
+func TestRabbitMQPropagation(t *testing.T) {
+	recorder := useRecorder(t)
+
+	// Headers as set by RabbitMQProducerService.Produce
+	ctx, produce := tracing.StartSpan(testContext(), "Produce Message", tracing.WithKind(tracing.KindProducer))
+	headers := amqp.Table{}
+	for k, v := range tracing.Get().Inject(ctx) {
+		headers[k] = v
+	}
+	produce.End(nil)
+
+	consumer := &RabbitMQConsumerService{config: &RabbitMQConsumerServiceConfig{
+		Queue:  "orders",
+		Script: "function run() {}",
+	}}
+	err := consumer.handleMessage(testContext(), amqp.Delivery{Headers: headers, Body: []byte("order")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Spans()
+	produceSpan := findSpan(t, spans, named("Produce Message"))
+	consumeSpan := findSpan(t, spans, named("Consume Queue orders"))
+
+	if consumeSpan.ParentID != produceSpan.ID {
+		t.Errorf("expected the consume span to continue %d, got %d", produceSpan.ID, consumeSpan.ParentID)
+	}
+	if consumeSpan.Err != nil {
+		t.Errorf("unexpected error %v", consumeSpan.Err)
+	}
+}
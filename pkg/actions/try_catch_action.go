@@ -0,0 +1,84 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/Causely/chaosmania/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TryCatch executes the fallback actions when its actions fail, or swallows the error
+// if there are none, to model graceful degradation
+type TryCatch struct{}
+
+type TryCatchConfig struct {
+	Actions  []ActionConfig `json:"actions"`
+	Fallback []ActionConfig `json:"fallback"`
+}
+
+func (a *TryCatch) Execute(ctx context.Context, cfg map[string]any) error {
+	config, err := a.parseConfig(cfg)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to parse config", zap.Error(err))
+		return err
+	}
+
+	tryCtx, done := explainAction(ctx, "try")
+	workload := Workload{Actions: config.Actions}
+	err = workload.Execute(tryCtx)
+	done(err)
+
+	if err == nil {
+		return nil
+	}
+
+	// The fallback can't help once the request is gone
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if len(config.Fallback) == 0 {
+		logger.FromContext(ctx).Warn("actions failed, ignoring the error", zap.Error(err))
+		return nil
+	}
+
+	logger.FromContext(ctx).Warn("actions failed, executing the fallback", zap.Error(err))
+
+	catchCtx, done := explainAction(ctx, "fallback")
+	fallback := Workload{Actions: config.Fallback}
+	err = fallback.Execute(catchCtx)
+	done(err)
+
+	return err
+}
+
+func (a *TryCatch) parseConfig(data map[string]any) (*TryCatchConfig, error) {
+	config, err := pkg.ParseConfig[TryCatchConfig](data)
+	if err != nil {
+		return nil, err
+	}
+
+	workload := Workload{Actions: config.Actions}
+	err = workload.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	fallback := Workload{Actions: config.Fallback}
+	err = fallback.Verify()
+	if err != nil {
+		return nil, fmt.Errorf("fallback: %w", err)
+	}
+
+	return config, nil
+}
+
+func (a *TryCatch) ParseConfig(data map[string]any) (any, error) {
+	return a.parseConfig(data)
+}
+
+func init() {
+	ACTIONS["TryCatch"] = &TryCatch{}
+}
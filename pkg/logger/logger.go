@@ -5,6 +5,7 @@ import (
 
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type ctxKey struct{}
@@ -24,5 +25,10 @@ func FromContext(ctx context.Context) *zap.Logger {
 		childLogger = childLogger.With(zap.String("span-id", spanID.String()))
 	}
 
+	if trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		// Skipped by the console encoder, the OpenTelemetry core uses the context to correlate exported logs with the span
+		childLogger = childLogger.With(zap.Field{Key: "context", Type: zapcore.SkipType, Interface: ctx})
+	}
+
 	return childLogger
 }
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.uber.org/zap/zapcore"
+)
+
+// openTelemetryEnabled is set once the global OpenTelemetry logger provider is installed
+var openTelemetryEnabled atomic.Bool
+
+// EnableOpenTelemetry starts writing to the OpenTelemetry cores, call it once the global
+// logger provider is installed
+func EnableOpenTelemetry() {
+	openTelemetryEnabled.Store(true)
+}
+
+// levelCore drops the entries below its level, and all entries until EnableOpenTelemetry is called.
+// zapcore.NewIncreaseLevelCore can't be used, the OpenTelemetry core is disabled until the logger provider is set.
+type levelCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+// NewOpenTelemetryCore writes the entries enabled by level to the global OpenTelemetry logger provider
+func NewOpenTelemetryCore(name string, level zapcore.LevelEnabler) zapcore.Core {
+	return &levelCore{Core: otelzap.NewCore(name), level: level}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return openTelemetryEnabled.Load() && c.level.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+
+	return c.Core.Check(entry, checked)
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+	sqltrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/database/sql"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	redistrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/redis/go-redis.v9"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Datadog uses the global Datadog tracer, which has to be started separately
+type Datadog struct{}
+
+func NewDatadog() Datadog {
+	return Datadog{}
+}
+
+type remoteParentKey struct{}
+
+type datadogSpan struct {
+	span tracer.Span
+}
+
+func (Datadog) Name() string {
+	return "datadog"
+}
+
+func datadogKind(kind SpanKind) string {
+	switch kind {
+	case KindServer:
+		return ext.SpanKindServer
+	case KindClient:
+		return ext.SpanKindClient
+	case KindProducer:
+		return ext.SpanKindProducer
+	case KindConsumer:
+		return ext.SpanKindConsumer
+	default:
+		return ext.SpanKindInternal
+	}
+}
+
+func (Datadog) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span) {
+	config := newSpanConfig(name, opts)
+
+	startOpts := []tracer.StartSpanOption{
+		tracer.ResourceName(name),
+		tracer.Tag(ext.SpanKind, datadogKind(config.Kind)),
+	}
+
+	if config.PeerService != "" {
+		// Datadog shows dependencies as services of their own
+		startOpts = append(startOpts,
+			tracer.ServiceName(config.PeerService),
+			tracer.Tag("peer.service", config.PeerService),
+			tracer.Tag("peer.namespace", config.PeerNamespace),
+		)
+	}
+
+	for k, v := range config.Attributes {
+		startOpts = append(startOpts, tracer.Tag(k, v))
+	}
+
+	if config.Link != nil {
+		// StartSpan instead of StartSpanFromContext, so the span starts a new trace
+		if linked, ok := tracer.SpanFromContext(config.Link); ok {
+			startOpts = append(startOpts, tracer.WithSpanLinks([]ddtrace.SpanLink{{
+				TraceID: linked.Context().TraceID(),
+				SpanID:  linked.Context().SpanID(),
+			}}))
+		}
+
+		span := tracer.StartSpan(config.Operation, startOpts...)
+		return tracer.ContextWithSpan(ctx, span), &datadogSpan{span: span}
+	}
+
+	if _, ok := tracer.SpanFromContext(ctx); !ok {
+		if parent, ok := ctx.Value(remoteParentKey{}).(ddtrace.SpanContext); ok {
+			startOpts = append(startOpts, tracer.ChildOf(parent))
+		}
+	}
+
+	span, ctx := tracer.StartSpanFromContext(ctx, config.Operation, startOpts...)
+	return ctx, &datadogSpan{span: span}
+}
+
+func (Datadog) Inject(ctx context.Context) map[string]string {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	carrier := tracer.TextMapCarrier{}
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		return nil
+	}
+
+	return carrier
+}
+
+func (Datadog) Extract(ctx context.Context, headers map[string]string) context.Context {
+	spanCtx, err := tracer.Extract(tracer.TextMapCarrier(headers))
+	if err != nil {
+		return ctx
+	}
+
+	// The tracer only picks up parent spans from the context, so StartSpan continues the remote one
+	return context.WithValue(ctx, remoteParentKey{}, spanCtx)
+}
+
+func (Datadog) WrapHandler(handler http.Handler, ignore func(*http.Request) bool) http.Handler {
+	return httptrace.WrapHandler(handler, "", "",
+		httptrace.WithResourceNamer(func(req *http.Request) string {
+			return req.Method + " " + req.URL.Path
+		}),
+		httptrace.WithIgnoreRequest(ignore),
+	)
+}
+
+func (Datadog) SetRoute(ctx context.Context, route string) {
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		span.SetTag(ext.ResourceName, route)
+		span.SetTag(ext.HTTPRoute, route)
+	}
+}
+
+func (Datadog) WrapTransport(transport http.RoundTripper) http.RoundTripper {
+	return httptrace.WrapRoundTripper(transport)
+}
+
+func (Datadog) InstrumentRedis(client *redis.Client, peerService string) error {
+	redistrace.WrapClient(client, redistrace.WithServiceName(peerService))
+	return nil
+}
+
+func (Datadog) OpenSQL(driver string, dsn string, options SQLOptions) (*sql.DB, error) {
+	return sqltrace.Open(driver, dsn, sqltrace.WithServiceName(options.PeerService))
+}
+
+func (s *datadogSpan) SetAttribute(key string, value any) {
+	s.span.SetTag(key, value)
+}
+
+func (s *datadogSpan) AddEvent(name string, attributes map[string]any) {
+	// Datadog spans have no events, record the latest one as tags
+	s.span.SetTag("event", name)
+	for k, v := range attributes {
+		s.span.SetTag("event."+k, v)
+	}
+}
+
+func (s *datadogSpan) End(err error) {
+	s.span.Finish(tracer.WithError(err))
+}
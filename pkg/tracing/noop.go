@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Noop is used if no tracing backend is enabled
+type Noop struct{}
+
+type noopSpan struct{}
+
+func (Noop) Name() string {
+	return "none"
+}
+
+func (Noop) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (Noop) Inject(ctx context.Context) map[string]string {
+	return nil
+}
+
+func (Noop) Extract(ctx context.Context, headers map[string]string) context.Context {
+	return ctx
+}
+
+func (Noop) WrapHandler(handler http.Handler, ignore func(*http.Request) bool) http.Handler {
+	return handler
+}
+
+func (Noop) SetRoute(ctx context.Context, route string) {}
+
+func (Noop) WrapTransport(transport http.RoundTripper) http.RoundTripper {
+	return transport
+}
+
+func (Noop) InstrumentRedis(client *redis.Client, peerService string) error {
+	return nil
+}
+
+func (Noop) OpenSQL(driver string, dsn string, options SQLOptions) (*sql.DB, error) {
+	return sql.Open(driver, dsn)
+}
+
+func (noopSpan) SetAttribute(key string, value any) {}
+
+func (noopSpan) AddEvent(name string, attributes map[string]any) {}
+
+func (noopSpan) End(err error) {}
@@ -0,0 +1,203 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+	"go.nhat.io/otelsql"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer and logger of chaosmania
+const InstrumentationName = "github.com/Causely/chaosmania"
+
+// OpenTelemetry uses the global OpenTelemetry tracer provider and propagator
+type OpenTelemetry struct {
+	mu sync.Mutex
+	// sqlDrivers are the names of the otelsql wrappers registered per database system
+	sqlDrivers map[string]string
+}
+
+func NewOpenTelemetry() *OpenTelemetry {
+	return &OpenTelemetry{
+		sqlDrivers: make(map[string]string),
+	}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (p *OpenTelemetry) Name() string {
+	return "opentelemetry"
+}
+
+func otelKind(kind SpanKind) trace.SpanKind {
+	switch kind {
+	case KindServer:
+		return trace.SpanKindServer
+	case KindClient:
+		return trace.SpanKindClient
+	case KindProducer:
+		return trace.SpanKindProducer
+	case KindConsumer:
+		return trace.SpanKindConsumer
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+func otelAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int(key, int(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+func otelAttributes(attributes map[string]any) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		kvs = append(kvs, otelAttribute(k, v))
+	}
+
+	return kvs
+}
+
+func (p *OpenTelemetry) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span) {
+	config := newSpanConfig(name, opts)
+
+	attributes := otelAttributes(config.Attributes)
+	if config.PeerService != "" {
+		attributes = append(attributes,
+			semconv.PeerService(config.PeerService),
+			attribute.String("peer.namespace", config.PeerNamespace),
+		)
+	}
+
+	startOpts := []trace.SpanStartOption{
+		trace.WithSpanKind(otelKind(config.Kind)),
+		trace.WithAttributes(attributes...),
+	}
+
+	if config.Link != nil {
+		startOpts = append(startOpts, trace.WithNewRoot(), trace.WithLinks(trace.LinkFromContext(config.Link)))
+	}
+
+	ctx, span := otel.GetTracerProvider().Tracer(InstrumentationName).Start(ctx, name, startOpts...)
+
+	return ctx, &otelSpan{span: span}
+}
+
+func (p *OpenTelemetry) Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+func (p *OpenTelemetry) Extract(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+func (p *OpenTelemetry) WrapHandler(handler http.Handler, ignore func(*http.Request) bool) http.Handler {
+	return otelhttp.NewHandler(handler, "server",
+		otelhttp.WithSpanNameFormatter(func(operation string, req *http.Request) string {
+			return req.URL.Path
+		}),
+		otelhttp.WithFilter(func(req *http.Request) bool {
+			return !ignore(req)
+		}),
+		otelhttp.WithTracerProvider(otel.GetTracerProvider()),
+		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
+	)
+}
+
+func (p *OpenTelemetry) SetRoute(ctx context.Context, route string) {
+	span := trace.SpanFromContext(ctx)
+	span.SetName(route)
+	span.SetAttributes(semconv.HTTPRoute(route))
+}
+
+func (p *OpenTelemetry) WrapTransport(transport http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(transport)
+}
+
+func (p *OpenTelemetry) InstrumentRedis(client *redis.Client, peerService string) error {
+	err := redisotel.InstrumentMetrics(client)
+	if err != nil {
+		return err
+	}
+
+	return redisotel.InstrumentTracing(client)
+}
+
+func (p *OpenTelemetry) OpenSQL(driver string, dsn string, options SQLOptions) (*sql.DB, error) {
+	p.mu.Lock()
+	name, ok := p.sqlDrivers[driver]
+	if !ok {
+		// Register the otelsql wrapper for the driver, the attributes of the first database opened are kept
+		var err error
+		name, err = otelsql.Register(driver,
+			otelsql.AllowRoot(),
+			otelsql.TraceQueryWithoutArgs(),
+			otelsql.TraceRowsClose(),
+			otelsql.TraceRowsAffected(),
+			otelsql.WithDatabaseName(options.Database),
+			otelsql.WithSystem(attribute.String("db.system", options.System)),
+			otelsql.WithDefaultAttributes(
+				semconv.ServerAddress(options.Host),
+				semconv.ServerPort(options.Port),
+			),
+		)
+		if err != nil {
+			p.mu.Unlock()
+			return nil, err
+		}
+
+		p.sqlDrivers[driver] = name
+	}
+	p.mu.Unlock()
+
+	return sql.Open(name, dsn)
+}
+
+func (s *otelSpan) SetAttribute(key string, value any) {
+	s.span.SetAttributes(otelAttribute(key, value))
+}
+
+func (s *otelSpan) AddEvent(name string, attributes map[string]any) {
+	s.span.AddEvent(name, trace.WithAttributes(otelAttributes(attributes)...))
+}
+
+func (s *otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	} else {
+		s.span.SetStatus(codes.Ok, "")
+	}
+
+	s.span.End()
+}
@@ -0,0 +1,156 @@
+// Package tracing instruments actions and services independently of the tracing backend.
+// The provider is selected once at startup, actions and services only use this package.
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+
+	"github.com/Causely/chaosmania/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+type SpanKind int
+
+const (
+	KindInternal SpanKind = iota
+	KindServer
+	KindClient
+	KindProducer
+	KindConsumer
+)
+
+// SpanConfig describes a span started by StartSpan
+type SpanConfig struct {
+	Kind SpanKind
+	// Operation groups spans in backends that distinguish it from the name, like Datadog
+	Operation string
+	// PeerService is the dependency called by client, producer and consumer spans
+	PeerService   string
+	PeerNamespace string
+	Attributes    map[string]any
+	// Link makes the span the root of a new trace, linked to the span in this context
+	Link context.Context
+}
+
+type SpanOption func(*SpanConfig)
+
+func WithKind(kind SpanKind) SpanOption {
+	return func(c *SpanConfig) {
+		c.Kind = kind
+	}
+}
+
+func WithOperation(operation string) SpanOption {
+	return func(c *SpanConfig) {
+		c.Operation = operation
+	}
+}
+
+func WithPeer(service string, namespace string) SpanOption {
+	return func(c *SpanConfig) {
+		c.PeerService = service
+		c.PeerNamespace = namespace
+	}
+}
+
+func WithAttribute(key string, value any) SpanOption {
+	return func(c *SpanConfig) {
+		if c.Attributes == nil {
+			c.Attributes = make(map[string]any)
+		}
+		c.Attributes[key] = value
+	}
+}
+
+// WithLinkFrom starts a new trace linked to the span in ctx, for work outliving the request of ctx
+func WithLinkFrom(ctx context.Context) SpanOption {
+	return func(c *SpanConfig) {
+		c.Link = ctx
+	}
+}
+
+func newSpanConfig(name string, opts []SpanOption) *SpanConfig {
+	config := &SpanConfig{Operation: name}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+type Span interface {
+	SetAttribute(key string, value any)
+	AddEvent(name string, attributes map[string]any)
+	// End finishes the span, marking it as failed if err is not nil
+	End(err error)
+}
+
+// SQLOptions describes the database opened by OpenSQL
+type SQLOptions struct {
+	// System is the database system, e.g. postgresql or mysql
+	System      string
+	Database    string
+	Host        string
+	Port        int
+	PeerService string
+}
+
+type Provider interface {
+	Name() string
+
+	// StartSpan starts a span as child of the span in ctx
+	StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span)
+
+	// Inject returns the headers propagating the span in ctx, Extract continues it
+	Inject(ctx context.Context) map[string]string
+	Extract(ctx context.Context, headers map[string]string) context.Context
+
+	// WrapHandler traces incoming requests unless ignore returns true
+	WrapHandler(handler http.Handler, ignore func(*http.Request) bool) http.Handler
+	// SetRoute names the server span of the request in ctx after its route
+	SetRoute(ctx context.Context, route string)
+
+	WrapTransport(transport http.RoundTripper) http.RoundTripper
+	InstrumentRedis(client *redis.Client, peerService string) error
+	OpenSQL(driver string, dsn string, options SQLOptions) (*sql.DB, error)
+}
+
+var (
+	mu       sync.RWMutex
+	provider Provider = Noop{}
+)
+
+// SetProvider selects the provider, it has to be called before services are loaded
+func SetProvider(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	provider = p
+}
+
+func Get() Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return provider
+}
+
+// FromEnvironment returns the provider enabled by DATADOG_ENABLED or OTEL_ENABLED
+func FromEnvironment() Provider {
+	if pkg.IsDatadogEnabled() {
+		return NewDatadog()
+	}
+
+	if pkg.IsOpenTelemetryEnabled() {
+		return NewOpenTelemetry()
+	}
+
+	return Noop{}
+}
+
+func StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span) {
+	return Get().StartSpan(ctx, name, opts...)
+}
@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const recorderParentHeader = "x-recorder-parent-id"
+
+// RecordedSpan is a span finished while the Recorder was the provider
+type RecordedSpan struct {
+	ID       int
+	ParentID int
+	// LinkID is the span linked with WithLinkFrom
+	LinkID int
+	Name   string
+	Config SpanConfig
+	Events []RecordedEvent
+	Err    error
+	Start  time.Time
+	End    time.Time
+}
+
+type RecordedEvent struct {
+	Name       string
+	Attributes map[string]any
+}
+
+// Recorder keeps finished spans in memory, so instrumentation can be checked in tests
+type Recorder struct {
+	mu     sync.Mutex
+	nextID int
+	spans  []RecordedSpan
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type recorderSpanKey struct{}
+
+type recorderSpan struct {
+	recorder *Recorder
+	mu       sync.Mutex
+	span     RecordedSpan
+}
+
+func (r *Recorder) Name() string {
+	return "recorder"
+}
+
+func parentID(ctx context.Context) int {
+	if parent, ok := ctx.Value(recorderSpanKey{}).(*recorderSpan); ok {
+		return parent.span.ID
+	}
+
+	return 0
+}
+
+func (r *Recorder) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span) {
+	r.mu.Lock()
+	r.nextID++
+	id := r.nextID
+	r.mu.Unlock()
+
+	config := newSpanConfig(name, opts)
+	span := &recorderSpan{
+		recorder: r,
+		span: RecordedSpan{
+			ID:     id,
+			Name:   name,
+			Config: *config,
+			Start:  time.Now(),
+		},
+	}
+
+	if config.Link != nil {
+		span.span.LinkID = parentID(config.Link)
+	} else {
+		span.span.ParentID = parentID(ctx)
+	}
+
+	return context.WithValue(ctx, recorderSpanKey{}, span), span
+}
+
+func (r *Recorder) Inject(ctx context.Context) map[string]string {
+	id := parentID(ctx)
+	if id == 0 {
+		return nil
+	}
+
+	return map[string]string{recorderParentHeader: strconv.Itoa(id)}
+}
+
+func (r *Recorder) Extract(ctx context.Context, headers map[string]string) context.Context {
+	id, err := strconv.Atoi(headers[recorderParentHeader])
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, recorderSpanKey{}, &recorderSpan{span: RecordedSpan{ID: id}})
+}
+
+// WrapHandler records a server span for every request, continuing the trace of the client
+func (r *Recorder) WrapHandler(handler http.Handler, ignore func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ignore(req) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		headers := map[string]string{recorderParentHeader: req.Header.Get(recorderParentHeader)}
+		ctx, span := r.StartSpan(r.Extract(req.Context(), headers), req.URL.Path, WithKind(KindServer))
+		defer span.End(nil)
+
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (r *Recorder) SetRoute(ctx context.Context, route string) {
+	if span, ok := ctx.Value(recorderSpanKey{}).(*recorderSpan); ok && span.recorder != nil {
+		span.mu.Lock()
+		span.span.Name = route
+		span.mu.Unlock()
+	}
+}
+
+// WrapTransport records a client span for every request and propagates it to the server
+func (r *Recorder) WrapTransport(transport http.RoundTripper) http.RoundTripper {
+	return recorderTransport{recorder: r, transport: transport}
+}
+
+type recorderTransport struct {
+	recorder  *Recorder
+	transport http.RoundTripper
+}
+
+func (t recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.recorder.StartSpan(req.Context(), "HTTP "+req.Method, WithKind(KindClient))
+
+	req = req.Clone(ctx)
+	for k, v := range t.recorder.Inject(ctx) {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	span.End(err)
+
+	return resp, err
+}
+
+func (r *Recorder) InstrumentRedis(client *redis.Client, peerService string) error {
+	return nil
+}
+
+func (r *Recorder) OpenSQL(driver string, dsn string, options SQLOptions) (*sql.DB, error) {
+	return sql.Open(driver, dsn)
+}
+
+// Spans returns the finished spans in the order they ended
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedSpan(nil), r.spans...)
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+}
+
+func (s *recorderSpan) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	WithAttribute(key, value)(&s.span.Config)
+}
+
+func (s *recorderSpan) AddEvent(name string, attributes map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.span.Events = append(s.span.Events, RecordedEvent{Name: name, Attributes: attributes})
+}
+
+func (s *recorderSpan) End(err error) {
+	s.mu.Lock()
+	s.span.Err = err
+	s.span.End = time.Now()
+	span := s.span
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.recorder.spans = append(s.recorder.spans, span)
+}
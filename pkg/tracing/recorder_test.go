@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// spanNamed returns the recorded span with the name, failing the test if there is none
+func spanNamed(t *testing.T, spans []RecordedSpan, name string) RecordedSpan {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	t.Fatalf("no span %q in %+v", name, spans)
+	return RecordedSpan{}
+}
+
+func TestRecorderParents(t *testing.T) {
+	r := NewRecorder()
+
+	ctx, parent := r.StartSpan(context.Background(), "parent")
+	_, child := r.StartSpan(ctx, "child", WithKind(KindClient), WithAttribute("key", "value"))
+	child.End(nil)
+	parent.End(nil)
+
+	spans := r.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	p, c := spanNamed(t, spans, "parent"), spanNamed(t, spans, "child")
+	if p.ParentID != 0 {
+		t.Errorf("expected parent to be a root span, got parent %d", p.ParentID)
+	}
+	if c.ParentID != p.ID {
+		t.Errorf("expected child of %d, got %d", p.ID, c.ParentID)
+	}
+	if c.Config.Kind != KindClient || c.Config.Attributes["key"] != "value" {
+		t.Errorf("unexpected child config %+v", c.Config)
+	}
+}
+
+func TestRecorderLink(t *testing.T) {
+	r := NewRecorder()
+
+	ctx, request := r.StartSpan(context.Background(), "request")
+	_, spawned := r.StartSpan(ctx, "spawned", WithLinkFrom(ctx))
+	spawned.End(nil)
+	request.End(nil)
+
+	req, s := spanNamed(t, r.Spans(), "request"), spanNamed(t, r.Spans(), "spawned")
+	if s.ParentID != 0 || s.LinkID != req.ID {
+		t.Errorf("expected a root span linked to %d, got parent %d and link %d", req.ID, s.ParentID, s.LinkID)
+	}
+}
+
+func TestRecorderInjectExtract(t *testing.T) {
+	r := NewRecorder()
+
+	if headers := r.Inject(context.Background()); len(headers) != 0 {
+		t.Errorf("expected no headers without a span, got %v", headers)
+	}
+
+	ctx, producer := r.StartSpan(context.Background(), "producer")
+	headers := r.Inject(ctx)
+	producer.End(nil)
+
+	_, consumer := r.StartSpan(r.Extract(context.Background(), headers), "consumer")
+	consumer.End(nil)
+
+	p, c := spanNamed(t, r.Spans(), "producer"), spanNamed(t, r.Spans(), "consumer")
+	if c.ParentID != p.ID {
+		t.Errorf("expected consumer to continue %d, got parent %d", p.ID, c.ParentID)
+	}
+}
+
+func TestRecorderHTTP(t *testing.T) {
+	r := NewRecorder()
+
+	handler := r.WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.SetRoute(req.Context(), "GET /items/{id}")
+	}), func(req *http.Request) bool {
+		return req.URL.Path == "/health"
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := &http.Client{Transport: r.WrapTransport(http.DefaultTransport)}
+	ctx, root := r.StartSpan(context.Background(), "root")
+	for _, path := range []string{"/items/1", "/health"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	root.End(nil)
+
+	spans := r.Spans()
+	for _, span := range spans {
+		if span.Name == "/health" {
+			t.Errorf("expected ignored requests not to be traced")
+		}
+	}
+
+	rootSpan := spanNamed(t, spans, "root")
+	serverSpan := spanNamed(t, spans, "GET /items/{id}")
+	if serverSpan.Config.Kind != KindServer {
+		t.Errorf("expected a server span, got kind %v", serverSpan.Config.Kind)
+	}
+
+	var clientSpan RecordedSpan
+	for _, span := range spans {
+		if span.ID == serverSpan.ParentID {
+			clientSpan = span
+		}
+	}
+	if clientSpan.Name != "HTTP GET" || clientSpan.Config.Kind != KindClient {
+		t.Errorf("expected the server span to continue a client span, got %+v", clientSpan)
+	}
+	if clientSpan.ParentID != rootSpan.ID {
+		t.Errorf("expected the client span to be a child of %d, got %d", rootSpan.ID, clientSpan.ParentID)
+	}
+}